@@ -0,0 +1,123 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// ControllerResolver resolves the controllers of the intermediate owners of the Pods, whatever their kind
+// (e.g. the ReplicaSets of Deployments and Argo Rollouts, or the ReplicationControllers of DeploymentConfigs).
+type ControllerResolver struct {
+	dynamic dynamic.Interface
+	mapper  meta.RESTMapper
+	// controllers caches the UID of the controller of each resolved owner by its UID.
+	controllers map[types.UID]types.UID
+}
+
+func NewControllerResolver(dynamicClient dynamic.Interface, mapper meta.RESTMapper) *ControllerResolver {
+	return &ControllerResolver{
+		dynamic:     dynamicClient,
+		mapper:      mapper,
+		controllers: make(map[types.UID]types.UID),
+	}
+}
+
+// IsOwnedBy returns true if the controller of the provided Pod, or the controller of its controller,
+// has the provided UID.
+func (r *ControllerResolver) IsOwnedBy(ctx context.Context, pod metav1.Object, uid types.UID) (bool, error) {
+	controllerRef := metav1.GetControllerOfNoCopy(pod)
+	if controllerRef == nil {
+		return false, nil
+	}
+	if controllerRef.UID == uid {
+		return true, nil
+	}
+
+	controller, ok := r.controllers[controllerRef.UID]
+	if !ok {
+		var err error
+		controller, err = r.controllerOf(ctx, pod.GetNamespace(), *controllerRef)
+		if err != nil {
+			return false, err
+		}
+		r.controllers[controllerRef.UID] = controller
+	}
+	return controller == uid, nil
+}
+
+// controllerOf returns the UID of the controller of the referenced object, or an empty UID if it has none.
+func (r *ControllerResolver) controllerOf(
+	ctx context.Context,
+	namespace string,
+	ref metav1.OwnerReference,
+) (types.UID, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return "", err
+	}
+	// the Pods controlled by objects that can't be resolved (e.g. unknown kinds or kinds the user
+	// can't read) are skipped
+	mapping, err := r.mapper.RESTMapping(gv.WithKind(ref.Kind).GroupKind(), gv.Version)
+	if meta.IsNoMatchError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find the resource of kind %s: %w", ref.Kind, err)
+	}
+	u, err := r.dynamic.Resource(mapping.Resource).Namespace(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if controllerRef := metav1.GetControllerOfNoCopy(u); controllerRef != nil {
+		return controllerRef.UID, nil
+	}
+	return "", nil
+}
+
+// PodSelector returns the selector of the Pods created by the provided object, or nil if it has none.
+// Both label selectors and plain label maps (e.g. ReplicationControllers) are supported.
+func PodSelector(u *unstructured.Unstructured) (*metav1.LabelSelector, error) {
+	selectorObj, found, err := unstructured.NestedMap(u.Object, "spec", "selector")
+	if err != nil || !found {
+		return nil, err
+	}
+	_, hasMatchLabels := selectorObj["matchLabels"]
+	_, hasMatchExpressions := selectorObj["matchExpressions"]
+	if hasMatchLabels || hasMatchExpressions {
+		var selector metav1.LabelSelector
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(selectorObj, &selector)
+		return &selector, err
+	}
+	matchLabels, _, err := unstructured.NestedStringMap(u.Object, "spec", "selector")
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.LabelSelector{MatchLabels: matchLabels}, nil
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/core"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"slices"
+	"time"
+)
+
+// deletionPollInterval is the time between two checks of a pending deletion.
+const deletionPollInterval = time.Second
+
+// finalizersTimeout is the time the finalizers of a deleted object are given to complete
+// before they are reported as blocking its deletion.
+var finalizersTimeout = 5 * time.Second
+
+var podsResource = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+// dependentPods returns the Pods controlled by the provided object, either directly (e.g. Jobs)
+// or through an intermediate controller (e.g. the ReplicaSets of Deployments).
+// Only the Pods matching the selector of the object are considered, if it has one.
+func (c Duplik8sClient) dependentPods(
+	ctx context.Context,
+	owner *unstructured.Unstructured,
+) ([]*unstructured.Unstructured, error) {
+	labelSelector, err := PodSelector(owner)
+	if err != nil {
+		return nil, err
+	}
+	selector := labels.Everything()
+	if labelSelector != nil {
+		selector, err = metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pods, err := c.dynamic.Resource(podsResource).
+		Namespace(owner.GetNamespace()).
+		List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	resolver := NewControllerResolver(c.dynamic, c.mapper)
+	var dependents []*unstructured.Unstructured
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		owned, err := resolver.IsOwnedBy(ctx, pod, owner.GetUID())
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			dependents = append(dependents, pod)
+		}
+	}
+	return dependents, nil
+}
+
+// waitUntilDeleted waits until the object with the provided UID and its dependent Pods are gone.
+// If the timeout expires while the object is blocked by its finalizers, a core.FinalizersError is returned.
+func (c Duplik8sClient) waitUntilDeleted(
+	ctx context.Context,
+	resource schema.GroupVersionResource,
+	obj core.DuplicatedObject,
+	uid types.UID,
+	dependents []*unstructured.Unstructured,
+	timeout time.Duration,
+) error {
+	var pending *unstructured.Unstructured
+	var pendingPods int
+	err := wait.PollUntilContextTimeout(ctx, deletionPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		pending, err = c.getWithUID(ctx, resource, obj.Namespace, obj.Name, uid)
+		if err != nil || pending != nil {
+			return false, err
+		}
+		pendingPods = 0
+		for _, pod := range dependents {
+			u, err := c.getWithUID(ctx, podsResource, pod.GetNamespace(), pod.GetName(), pod.GetUID())
+			if err != nil {
+				return false, err
+			}
+			if u != nil {
+				pendingPods++
+			}
+		}
+		return pendingPods == 0, nil
+	})
+	if err == nil {
+		return nil
+	}
+	if !wait.Interrupted(err) {
+		return err
+	}
+
+	err = fmt.Errorf("timed out after %s waiting for the deletion", timeout)
+	if pending != nil {
+		if finalizers := blockingFinalizers(pending); len(finalizers) > 0 {
+			return fmt.Errorf("%w: %w", err, &core.FinalizersError{Finalizers: finalizers})
+		}
+		return err
+	}
+	return fmt.Errorf("%w: %d dependent pods still exist", err, pendingPods)
+}
+
+// checkFinalizers waits shortly for the deletion of the provided object, and returns a core.FinalizersError
+// if it's still blocked by its finalizers afterward. Finalizers that complete quickly are not reported.
+func (c Duplik8sClient) checkFinalizers(
+	ctx context.Context,
+	resource schema.GroupVersionResource,
+	obj core.DuplicatedObject,
+) error {
+	var finalizers []string
+	err := wait.PollUntilContextTimeout(ctx, deletionPollInterval, finalizersTimeout, true, func(ctx context.Context) (bool, error) {
+		u, err := c.dynamic.Resource(resource).Namespace(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		finalizers = blockingFinalizers(u)
+		return len(finalizers) == 0, nil
+	})
+	if wait.Interrupted(err) && len(finalizers) > 0 {
+		return &core.FinalizersError{Finalizers: finalizers}
+	}
+	return err
+}
+
+// getWithUID returns the object with the provided name and UID, or nil if it doesn't exist anymore.
+func (c Duplik8sClient) getWithUID(
+	ctx context.Context,
+	resource schema.GroupVersionResource,
+	namespace string,
+	name string,
+	uid types.UID,
+) (*unstructured.Unstructured, error) {
+	u, err := c.dynamic.Resource(resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.GetUID() != uid {
+		return nil, nil
+	}
+	return u, nil
+}
+
+// blockingFinalizers returns the finalizers preventing the deletion of the provided object,
+// ignoring the ones used by the garbage collector to delete its dependents.
+func blockingFinalizers(u *unstructured.Unstructured) []string {
+	if u.GetDeletionTimestamp() == nil {
+		return nil
+	}
+	return slices.DeleteFunc(slices.Clone(u.GetFinalizers()), func(f string) bool {
+		return f == metav1.FinalizerDeleteDependents || f == metav1.FinalizerOrphanDependents
+	})
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"testing"
+	"time"
+)
+
+var (
+	deploymentGVK = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	replicaSetGVK = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}
+	podGVK        = schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+)
+
+func newUnstructured(
+	gvk schema.GroupVersionKind,
+	name string,
+	uid string,
+	owner *unstructured.Unstructured,
+) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(name)
+	u.SetNamespace("default")
+	u.SetUID(types.UID(uid))
+	if owner != nil {
+		controller := true
+		u.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: owner.GetAPIVersion(),
+			Kind:       owner.GetKind(),
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+			Controller: &controller,
+		}})
+	}
+	return u
+}
+
+func newTestClient(objs ...runtime.Object) (Duplik8sClient, *dynamicfake.FakeDynamicClient) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range []schema.GroupVersionKind{deploymentGVK, replicaSetGVK, podGVK} {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+			{Group: "apps", Version: "v1", Resource: "replicasets"}: "ReplicaSetList",
+			{Version: "v1", Resource: "pods"}:                       "PodList",
+		},
+		objs...,
+	)
+	return Duplik8sClient{dynamic: dynamic, mapper: mapper}, dynamic
+}
+
+func newDuplicatedObject(u *unstructured.Unstructured) core.DuplicatedObject {
+	return core.DuplicatedObject{Name: u.GetName(), Namespace: u.GetNamespace(), ObjectKind: u.GetObjectKind()}
+}
+
+// setFinalizersTimeout overrides the time given to the finalizers of the deleted objects for the provided test.
+func setFinalizersTimeout(t *testing.T, timeout time.Duration) {
+	previous := finalizersTimeout
+	finalizersTimeout = timeout
+	t.Cleanup(func() { finalizersTimeout = previous })
+}
+
+func Test_Delete_UnknownKind(t *testing.T) {
+	client, _ := newTestClient()
+	obj := newUnstructured(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}, "foo", "1", nil)
+	err := client.Delete(context.Background(), newDuplicatedObject(obj), core.DeleteOpts{})
+	assert.ErrorContains(t, err, "failed to find the resource of kind Foo")
+}
+
+func Test_Delete(t *testing.T) {
+	deploy := newUnstructured(deploymentGVK, "app-duplik8ted", "deploy-uid", nil)
+	client, dynamic := newTestClient(deploy)
+
+	err := client.Delete(context.Background(), newDuplicatedObject(deploy), core.DeleteOpts{})
+	assert.NoError(t, err)
+	_, err = dynamic.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).
+		Namespace("default").
+		Get(context.Background(), "app-duplik8ted", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func Test_Delete_WaitForDependentPods(t *testing.T) {
+	deploy := newUnstructured(deploymentGVK, "app-duplik8ted", "deploy-uid", nil)
+	rs := newUnstructured(replicaSetGVK, "app-duplik8ted-abc", "rs-uid", deploy)
+	pod := newUnstructured(podGVK, "app-duplik8ted-abc-xyz", "pod-uid", rs)
+	other := newUnstructured(podGVK, "other", "other-uid", nil)
+	client, _ := newTestClient(deploy, rs, pod, other)
+
+	dependents, err := client.dependentPods(context.Background(), deploy)
+	assert.NoError(t, err)
+	assert.Len(t, dependents, 1)
+	assert.Equal(t, "app-duplik8ted-abc-xyz", dependents[0].GetName())
+
+	// the fake client doesn't garbage collect the dependents, so the Pod is never deleted
+	err = client.Delete(context.Background(), newDuplicatedObject(deploy), core.DeleteOpts{
+		Wait:        true,
+		WaitTimeout: 10 * time.Millisecond,
+	})
+	assert.ErrorContains(t, err, "1 dependent pods still exist")
+}
+
+func Test_Delete_BlockedByFinalizers(t *testing.T) {
+	deploy := newUnstructured(deploymentGVK, "app-duplik8ted", "deploy-uid", nil)
+	deploy.SetFinalizers([]string{"example.com/protect", metav1.FinalizerDeleteDependents})
+	deploy.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	client, dynamic := newTestClient(deploy)
+	// the fake client ignores the finalizers, so deletions are no-ops
+	dynamic.PrependReactor("delete", "*", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	setFinalizersTimeout(t, 10*time.Millisecond)
+
+	err := client.Delete(context.Background(), newDuplicatedObject(deploy), core.DeleteOpts{})
+	var finalizersErr *core.FinalizersError
+	assert.True(t, errors.As(err, &finalizersErr))
+	assert.Equal(t, []string{"example.com/protect"}, finalizersErr.Finalizers)
+
+	err = client.Delete(context.Background(), newDuplicatedObject(deploy), core.DeleteOpts{
+		Wait:        true,
+		WaitTimeout: 10 * time.Millisecond,
+	})
+	assert.ErrorContains(t, err, "timed out")
+	assert.True(t, errors.As(err, &finalizersErr))
+}
+
+func Test_Delete_TransientFinalizers(t *testing.T) {
+	deploy := newUnstructured(deploymentGVK, "app-duplik8ted", "deploy-uid", nil)
+	client, dynamic := newTestClient(deploy)
+	// the object is still there right after the deletion, but its finalizers complete shortly after
+	pending := deploy.DeepCopy()
+	pending.SetFinalizers([]string{"example.com/cleanup"})
+	pending.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	gets := 0
+	dynamic.PrependReactor("get", "deployments", func(clienttesting.Action) (bool, runtime.Object, error) {
+		gets++
+		if gets == 1 {
+			return true, pending, nil
+		}
+		return false, nil, nil
+	})
+
+	err := client.Delete(context.Background(), newDuplicatedObject(deploy), core.DeleteOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, gets)
+}
+
+func Test_DependentPods_Selector(t *testing.T) {
+	deploy := newUnstructured(deploymentGVK, "app-duplik8ted", "deploy-uid", nil)
+	assert.NoError(t, unstructured.SetNestedStringMap(
+		deploy.Object,
+		map[string]string{"app": "app-duplik8ted"},
+		"spec", "selector", "matchLabels",
+	))
+	rs := newUnstructured(replicaSetGVK, "app-duplik8ted-abc", "rs-uid", deploy)
+	pod := newUnstructured(podGVK, "app-duplik8ted-abc-xyz", "pod-uid", rs)
+	pod.SetLabels(map[string]string{"app": "app-duplik8ted"})
+	other := newUnstructured(podGVK, "other", "other-uid", nil)
+	other.SetLabels(map[string]string{"app": "other"})
+	client, dynamic := newTestClient(deploy, rs, pod, other)
+
+	dependents, err := client.dependentPods(context.Background(), deploy)
+	assert.NoError(t, err)
+	assert.Len(t, dependents, 1)
+	assert.Equal(t, "app-duplik8ted-abc-xyz", dependents[0].GetName())
+	// only the Pods matching the selector are listed
+	for _, action := range dynamic.Actions() {
+		if list, ok := action.(clienttesting.ListAction); ok {
+			assert.Equal(t, "app=app-duplik8ted", list.GetListRestrictions().Labels.String())
+		}
+	}
+}
+
+func Test_DependentPods_UnresolvableControllers(t *testing.T) {
+	job := newUnstructured(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, "job-duplik8ted", "job-uid", nil)
+	pod := newUnstructured(podGVK, "job-duplik8ted-xyz", "pod-uid", job)
+	// controlled by a kind that isn't served by the cluster
+	unknown := newUnstructured(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}, "foo", "foo-uid", nil)
+	unknownPod := newUnstructured(podGVK, "foo-xyz", "foo-pod-uid", unknown)
+	// controlled by a ReplicaSet the user can't read
+	rs := newUnstructured(replicaSetGVK, "app-abc", "rs-uid", nil)
+	forbiddenPod := newUnstructured(podGVK, "app-abc-xyz", "rs-pod-uid", rs)
+	client, dynamic := newTestClient(pod, unknownPod, forbiddenPod)
+	dynamic.PrependReactor("get", "replicasets", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "replicasets"}, "app-abc", nil)
+	})
+
+	dependents, err := client.dependentPods(context.Background(), job)
+	assert.NoError(t, err)
+	assert.Len(t, dependents, 1)
+	assert.Equal(t, "job-duplik8ted-xyz", dependents[0].GetName())
+}
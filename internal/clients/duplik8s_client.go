@@ -18,11 +18,19 @@ package clients
 
 import (
 	"context"
+	"fmt"
 	"github.com/telemaco019/duplik8s/internal/core"
 	"github.com/telemaco019/duplik8s/internal/utils"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/restmapper"
 	"slices"
@@ -32,6 +40,10 @@ import (
 type Duplik8sClient struct {
 	dynamic   dynamic.Interface
 	discovery discovery.DiscoveryInterface
+	// mapper caches the discovered resources for the lifetime of the client
+	mapper meta.RESTMapper
+	// kubeOptions are used for falling back to the kubeconfig user when the identity can't be reviewed
+	kubeOptions utils.KubeOptions
 }
 
 func NewDuplik8sClient(opts utils.KubeOptions) (*Duplik8sClient, error) {
@@ -46,8 +58,10 @@ func NewDuplik8sClient(opts utils.KubeOptions) (*Duplik8sClient, error) {
 	}
 
 	return &Duplik8sClient{
-		dynamic:   dynamic,
-		discovery: discovery,
+		dynamic:     dynamic,
+		discovery:   discovery,
+		mapper:      restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discovery)),
+		kubeOptions: opts,
 	}, nil
 }
 
@@ -69,49 +83,92 @@ func (c Duplik8sClient) ListDuplicable(
 	return objs, nil
 }
 
+// Delete deletes the provided duplicated object. If the object is still there shortly after the deletion,
+// because finalizers are blocking it, a core.FinalizersError is returned.
+// With opts.Wait, it waits until the object and its dependent Pods are gone.
 func (c Duplik8sClient) Delete(
 	ctx context.Context,
 	obj core.DuplicatedObject,
+	opts core.DeleteOpts,
 ) error {
-	// Get a RESTMapper
-	resources, err := restmapper.GetAPIGroupResources(c.discovery)
+	gvk := obj.ObjectKind.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
 	if err != nil {
-		panic(err.Error())
+		return fmt.Errorf("failed to find the resource of kind %s: %w", gvk.Kind, err)
 	}
-	restMapper := restmapper.NewDiscoveryRESTMapper(resources)
+	resource := c.dynamic.Resource(mapping.Resource).Namespace(obj.Namespace)
 
-	// Convert GroupVersionKind to GroupVersionResource
-	mapping, err := restMapper.RESTMapping(
-		obj.ObjectKind.GroupVersionKind().GroupKind(),
-		obj.ObjectKind.GroupVersionKind().Version,
-	)
+	// Delete dependents in background by default, otherwise the Pods of duplicated Jobs would be orphaned
+	propagation := opts.PropagationPolicy
+	if propagation == "" {
+		propagation = metav1.DeletePropagationBackground
+	}
+
+	// The dependent Pods are collected before the deletion, since their owners may be deleted first
+	var dependents []*unstructured.Unstructured
+	var uid types.UID
+	if opts.Wait {
+		u, err := resource.Get(ctx, obj.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		uid = u.GetUID()
+		if propagation != metav1.DeletePropagationOrphan {
+			dependents, err = c.dependentPods(ctx, u)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = resource.Delete(ctx, obj.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
 	if err != nil {
-		panic(err.Error())
+		return err
+	}
+	if opts.Wait {
+		return c.waitUntilDeleted(ctx, mapping.Resource, obj, uid, dependents, opts.WaitTimeout)
 	}
 
-	return c.dynamic.Resource(mapping.Resource).Namespace(obj.Namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
+	return c.checkFinalizers(ctx, mapping.Resource, obj)
 }
 
+func (c Duplik8sClient) Get(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	namespace string,
+	name string,
+) (*unstructured.Unstructured, error) {
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the resource of kind %s: %w", gvk.Kind, err)
+	}
+	return c.dynamic.Resource(mapping.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ListDuplicated returns the duplicated resources of every kind the user is allowed to list,
+// including the custom resources duplicated with a custom Pod template path.
 func (c Duplik8sClient) ListDuplicated(
 	ctx context.Context,
 	namespace string,
 ) ([]core.DuplicatedObject, error) {
-	_, apiResourceLists, err := c.discovery.ServerGroupsAndResources()
-	if err != nil {
+	// Only consider the preferred version of each resource, to avoid listing the same objects twice
+	apiResourceLists, err := c.discovery.ServerPreferredNamespacedResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
 		return nil, err
 	}
 
 	resources := make([]core.DuplicatedObject, 0)
+	// the leases of the shell sessions are listed once, the first time a duplicated resource references one
+	var leases map[types.NamespacedName]*coordinationv1.Lease
 	for _, apiResourceList := range apiResourceLists {
 		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
 		if err != nil {
 			return nil, err
 		}
 		for _, apiResource := range apiResourceList.APIResources {
-			// Skip non-namespaced resources
-			if !apiResource.Namespaced {
-				continue
-			}
 			// Skip subresources
 			if strings.Contains(apiResource.Name, "/") {
 				continue
@@ -120,11 +177,6 @@ func (c Duplik8sClient) ListDuplicated(
 			if !slices.Contains(apiResource.Verbs, "list") {
 				continue
 			}
-			// TODO: remove this when duplik8s will support all resources
-			// Skip resources that are not Pods, Deployments, or StatefulSets
-			if apiResource.Kind != "Pod" && apiResource.Kind != "Deployment" && apiResource.Kind != "StatefulSet" {
-				continue
-			}
 
 			gvr := schema.GroupVersionResource{
 				Group:    gv.Group,
@@ -136,19 +188,69 @@ func (c Duplik8sClient) ListDuplicated(
 				List(ctx, metav1.ListOptions{
 					LabelSelector: core.LABEL_DUPLICATED + "=true",
 				})
+			// Skip resources that the user is not allowed to list
+			if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
 
 			for _, u := range unstructuredList.Items {
+				leaseName, hasSession := u.GetAnnotations()[core.ANNOTATION_SESSION_LEASE]
+				if hasSession && leases == nil {
+					leases, err = c.listSessionLeases(ctx, namespace)
+					if err != nil {
+						return nil, err
+					}
+				}
+				var sessionExpiresAt *metav1.Time
+				if lease, ok := leases[types.NamespacedName{Namespace: u.GetNamespace(), Name: leaseName}]; ok {
+					sessionExpiresAt = core.SessionExpiresAt(lease)
+				} else if hasSession {
+					sessionExpiresAt = core.MissingSessionExpiresAt(u.GetCreationTimestamp())
+				}
 				resources = append(resources, core.DuplicatedObject{
 					Name:              u.GetName(),
 					Namespace:         u.GetNamespace(),
 					ObjectKind:        u.GetObjectKind(),
 					CreationTimestamp: u.GetCreationTimestamp(),
+					Labels:            u.GetLabels(),
+					Status:            objectStatus(&u),
+					Node:              objectNode(&u),
+					Source:            core.ParseSourceObject(u.GetAnnotations()),
+					Options:           core.ParseOptions(u.GetAnnotations()),
+					Creator:           u.GetAnnotations()[core.ANNOTATION_CREATOR],
+					Note:              u.GetAnnotations()[core.ANNOTATION_NOTE],
+					ExpiresAt:         core.ParseExpiresAt(u.GetAnnotations()),
+					SessionExpiresAt:  sessionExpiresAt,
+					Object:            u.Object,
 				})
 			}
 		}
 	}
 	return resources, nil
 }
+
+// listSessionLeases returns the leases of the shell sessions in the provided namespace, by namespace and name.
+func (c Duplik8sClient) listSessionLeases(
+	ctx context.Context,
+	namespace string,
+) (map[types.NamespacedName]*coordinationv1.Lease, error) {
+	unstructuredList, err := c.dynamic.Resource(coordinationv1.SchemeGroupVersion.WithResource("leases")).
+		Namespace(namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: core.LABEL_SESSION + "=true"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the session leases: %w", err)
+	}
+	leases := make(map[types.NamespacedName]*coordinationv1.Lease, len(unstructuredList.Items))
+	for _, u := range unstructuredList.Items {
+		var lease coordinationv1.Lease
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &lease)
+		if err != nil {
+			return nil, err
+		}
+		leases[types.NamespacedName{Namespace: lease.Namespace, Name: lease.Name}] = &lease
+	}
+	return leases, nil
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+	"testing"
+	"time"
+)
+
+// preferredResourcesDiscovery is a fake discovery client returning its resources as the preferred ones.
+type preferredResourcesDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (d preferredResourcesDiscovery) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	return d.Resources, nil
+}
+
+func Test_ListDuplicated_AllKinds(t *testing.T) {
+	listVerbs := metav1.Verbs{"get", "list", "delete"}
+	discovery := preferredResourcesDiscovery{&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: listVerbs},
+					{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+					{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: listVerbs},
+				},
+			},
+			{
+				GroupVersion: "example.com/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "workers", Kind: "Worker", Namespaced: true, Verbs: listVerbs},
+					{Name: "workers/status", Kind: "Worker", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+				},
+			},
+		},
+	}}}
+	pod := newUnstructured(podGVK, "nginx-duplik8ted", "1", nil)
+	pod.SetLabels(map[string]string{core.LABEL_DUPLICATED: "true"})
+	// a custom resource duplicated with --template-path
+	workerGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Worker"}
+	worker := newUnstructured(workerGVK, "worker-duplik8ted", "2", nil)
+	worker.SetLabels(map[string]string{core.LABEL_DUPLICATED: "true"})
+	source := newUnstructured(workerGVK, "worker", "3", nil)
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Version: "v1", Resource: "pods"}:                          "PodList",
+			{Version: "v1", Resource: "secrets"}:                       "SecretList",
+			{Group: "example.com", Version: "v1", Resource: "workers"}: "WorkerList",
+		},
+		pod,
+		worker,
+		source,
+	)
+	// the kinds the user isn't allowed to list are skipped
+	dynamic.PrependReactor("list", "secrets", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", nil)
+	})
+	client := Duplik8sClient{dynamic: dynamic, discovery: discovery}
+
+	objs, err := client.ListDuplicated(context.Background(), "default")
+	assert.NoError(t, err)
+	names := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		names = append(names, obj.Name)
+	}
+	assert.Equal(t, []string{"nginx-duplik8ted", "worker-duplik8ted"}, names)
+
+	listed := make([]string, 0)
+	for _, action := range dynamic.Actions() {
+		if action.GetVerb() == "list" {
+			listed = append(listed, action.GetResource().Resource)
+		}
+	}
+	assert.Equal(t, []string{"pods", "secrets", "workers"}, listed)
+}
+
+func Test_ListDuplicated_SessionLeases(t *testing.T) {
+	discovery := preferredResourcesDiscovery{&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "delete"}},
+				},
+			},
+		},
+	}}}
+	creationTimestamp := metav1.NewTime(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC))
+	newPod := func(name, lease string) *unstructured.Unstructured {
+		pod := newUnstructured(podGVK, name, name+"-uid", nil)
+		pod.SetLabels(map[string]string{core.LABEL_DUPLICATED: "true"})
+		pod.SetCreationTimestamp(creationTimestamp)
+		if lease != "" {
+			pod.SetAnnotations(map[string]string{core.ANNOTATION_SESSION_LEASE: lease})
+		}
+		return pod
+	}
+	newLease := func(name string, holder *string) *unstructured.Unstructured {
+		renewTime := metav1.NewMicroTime(creationTimestamp.Add(time.Hour))
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&coordinationv1.Lease{
+			TypeMeta: metav1.TypeMeta{APIVersion: "coordination.k8s.io/v1", Kind: "Lease"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{core.LABEL_SESSION: "true"},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       holder,
+				RenewTime:            &renewTime,
+				LeaseDurationSeconds: ptr.To[int32](60),
+			},
+		})
+		assert.NoError(t, err)
+		return &unstructured.Unstructured{Object: obj}
+	}
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Version: "v1", Resource: "pods"}:                                 "PodList",
+			{Group: "coordination.k8s.io", Version: "v1", Resource: "leases"}: "LeaseList",
+		},
+		newPod("no-session", ""),
+		newPod("active", "lease-active"),
+		newPod("released", "lease-released"),
+		// the lease couldn't be created
+		newPod("missing", "lease-missing"),
+		newLease("lease-active", ptr.To("laptop")),
+		newLease("lease-released", nil),
+	)
+	client := Duplik8sClient{dynamic: dynamic, discovery: discovery}
+
+	objs, err := client.ListDuplicated(context.Background(), "default")
+	assert.NoError(t, err)
+	sessions := make(map[string]*metav1.Time)
+	for _, obj := range objs {
+		sessions[obj.Name] = obj.SessionExpiresAt
+	}
+	assert.Nil(t, sessions["no-session"])
+	assert.Equal(t, creationTimestamp.Add(time.Hour+time.Minute), sessions["active"].Time.UTC())
+	assert.Nil(t, sessions["released"])
+	assert.Equal(t, creationTimestamp.Add(time.Minute), sessions["missing"].Time.UTC())
+
+	// the leases are listed once, selected by their label
+	var leaseActions []string
+	for _, action := range dynamic.Actions() {
+		if action.GetResource().Resource != "leases" {
+			continue
+		}
+		leaseActions = append(leaseActions, action.GetVerb())
+		if list, ok := action.(clienttesting.ListAction); ok {
+			assert.Equal(t, core.LABEL_SESSION+"=true", list.GetListRestrictions().Labels.String())
+		}
+	}
+	assert.Equal(t, []string{"list"}, leaseActions)
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CurrentUser returns the name of the user the client is authenticated as, according to a SelfSubjectReview.
+// If the API server doesn't support SelfSubjectReviews (Kubernetes < 1.28) or the review fails,
+// the name of the user of the kubeconfig context is returned instead.
+func (c Duplik8sClient) CurrentUser(ctx context.Context) (string, error) {
+	username, err := c.reviewSelfSubject(ctx)
+	if err == nil && username != "" {
+		return username, nil
+	}
+	if user := utils.KubeconfigUser(c.kubeOptions.Kubeconfig, c.kubeOptions.Kubecontext); user != "" {
+		return user, nil
+	}
+	if err == nil {
+		err = errors.New("the review returned no username")
+	}
+	return "", fmt.Errorf("failed to determine the current user: %w", err)
+}
+
+func (c Duplik8sClient) reviewSelfSubject(ctx context.Context) (string, error) {
+	review := &unstructured.Unstructured{}
+	review.SetGroupVersionKind(authenticationv1.SchemeGroupVersion.WithKind("SelfSubjectReview"))
+	u, err := c.dynamic.Resource(authenticationv1.SchemeGroupVersion.WithResource("selfsubjectreviews")).
+		Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	var reviewed authenticationv1.SelfSubjectReview
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &reviewed); err != nil {
+		return "", err
+	}
+	return reviewed.Status.UserInfo.Username, nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: alice@dev
+clusters:
+- name: dev
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: alice@dev
+  user:
+    token: token
+`
+
+func newIdentityClient(t *testing.T, reaction clienttesting.ReactionFunc) Duplik8sClient {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600))
+	dynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamic.PrependReactor("create", "selfsubjectreviews", reaction)
+	return Duplik8sClient{dynamic: dynamic, kubeOptions: utils.KubeOptions{Kubeconfig: kubeconfig}}
+}
+
+func Test_CurrentUser(t *testing.T) {
+	client := newIdentityClient(t, func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		assert.NoError(t, unstructured.SetNestedField(review.Object, "alice@example.com", "status", "userInfo", "username"))
+		return true, review, nil
+	})
+	user, err := client.CurrentUser(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user)
+}
+
+func Test_CurrentUser_FallbackToKubeconfig(t *testing.T) {
+	client := newIdentityClient(t, func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("the server could not find the requested resource")
+	})
+	user, err := client.CurrentUser(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@dev", user)
+}
@@ -18,19 +18,23 @@ package clients
 
 import (
 	"context"
+	"fmt"
 	"github.com/telemaco019/duplik8s/internal/core"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	"path"
+	"slices"
 )
 
 type PodConfigurator struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
 	options   core.DuplicateOpts
 }
 
 func NewConfigurator(
-	clientset *kubernetes.Clientset,
+	clientset kubernetes.Interface,
 	options core.DuplicateOpts,
 ) PodConfigurator {
 	return PodConfigurator{
@@ -44,16 +48,32 @@ func (c PodConfigurator) OverrideSpec(
 	namespace string,
 	podSpec *v1.PodSpec,
 ) error {
-	// Override command
-	if c.options.Command != nil {
-		for i := range podSpec.Containers {
-			podSpec.Containers[i].Command = c.options.Command
-			podSpec.Containers[i].Args = c.options.Args
-			podSpec.Containers[i].ReadinessProbe = c.options.ReadinessProbe
-			podSpec.Containers[i].LivenessProbe = c.options.LivenessProbe
-			podSpec.Containers[i].ReadinessProbe = c.options.ReadinessProbe
-			podSpec.Containers[i].StartupProbe = c.options.StartupProbe
-		}
+	// Remove the init containers, except native sidecars and the ones to keep
+	err := c.filterInitContainers(podSpec)
+	if err != nil {
+		return err
+	}
+
+	// Override the command and the probes of the targeted containers
+	targeted, shared, err := c.targetedContainers(*podSpec)
+	if err != nil {
+		return err
+	}
+	for i := range podSpec.InitContainers {
+		// the shared command would prevent the init containers from completing, so only native sidecars,
+		// which run along the other containers, get it
+		container := &podSpec.InitContainers[i]
+		getsShared := shared[container.Name] && isNativeSidecar(*container)
+		overridden := targeted[container.Name] && c.overrideContainer(container, getsShared)
+		// the debug tools are not mounted in the init containers
+		c.overridePolicies(container, targeted[container.Name], overridden, nil)
+	}
+	idle := make(map[string]bool)
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		overridden := targeted[container.Name] && c.overrideContainer(container, shared[container.Name])
+		c.overridePolicies(container, targeted[container.Name], overridden, c.alwaysSucceedCommand())
+		idle[container.Name] = overridden
 	}
 
 	hasMountOncePvc, err := c.hasMountOncePvc(ctx, namespace, *podSpec)
@@ -67,14 +87,271 @@ func (c PodConfigurator) OverrideSpec(
 		podSpec.NodeName = ""
 	}
 
-	// Remove init containers
-	if !c.options.PreserveInitContainers {
-		podSpec.InitContainers = nil
+	if c.options.DebugTools {
+		injectDebugTools(podSpec, c.options.DebugToolsImage, idle)
 	}
 
 	return nil
 }
 
+// filterInitContainers removes the init containers of the Pod. Native sidecars (i.e. init containers
+// with restart policy Always) are kept, since the other containers usually depend on them.
+func (c PodConfigurator) filterInitContainers(podSpec *v1.PodSpec) error {
+	for _, name := range slices.Concat(c.options.KeepInitContainers, c.options.DropInitContainers) {
+		found := slices.ContainsFunc(podSpec.InitContainers, func(container v1.Container) bool {
+			return container.Name == name
+		})
+		if !found {
+			return fmt.Errorf("init container %q not found", name)
+		}
+	}
+
+	initContainers := make([]v1.Container, 0, len(podSpec.InitContainers))
+	for _, container := range podSpec.InitContainers {
+		keep := c.options.PreserveInitContainers ||
+			isNativeSidecar(container) ||
+			slices.Contains(c.options.KeepInitContainers, container.Name)
+		if keep && !slices.Contains(c.options.DropInitContainers, container.Name) {
+			initContainers = append(initContainers, container)
+		}
+	}
+	podSpec.InitContainers = initContainers
+	return nil
+}
+
+func isNativeSidecar(container v1.Container) bool {
+	return container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways
+}
+
+// targetedContainers returns the names of the containers targeted by the overrides, and the ones
+// targeted by the overrides shared by all the containers. The containers with specific overrides are
+// targeted only by them, unless they are also provided by name. Init containers are only targeted if
+// provided by name. If no container other than init containers has been provided, all the containers
+// are targeted. An error is returned if any of the containers referenced by the options doesn't exist.
+func (c PodConfigurator) targetedContainers(podSpec v1.PodSpec) (map[string]bool, map[string]bool, error) {
+	for _, policies := range []core.OverridePolicies{
+		c.options.LivenessProbe,
+		c.options.ReadinessProbe,
+		c.options.StartupProbe,
+		c.options.PostStartHook,
+		c.options.PreStopHook,
+	} {
+		for name := range policies {
+			if name != "" && !hasContainer(podSpec, name) {
+				return nil, nil, fmt.Errorf("container %q not found", name)
+			}
+		}
+	}
+
+	targeted := make(map[string]bool)
+	shared := make(map[string]bool)
+	for _, name := range c.options.Containers {
+		if !hasContainer(podSpec, name) {
+			return nil, nil, fmt.Errorf("container %q not found", name)
+		}
+		targeted[name] = true
+		shared[name] = true
+	}
+	for name := range c.options.ContainerOverrides {
+		if !hasContainer(podSpec, name) {
+			return nil, nil, fmt.Errorf("container %q not found", name)
+		}
+		targeted[name] = true
+	}
+	targetsContainers := slices.ContainsFunc(podSpec.Containers, func(container v1.Container) bool {
+		return targeted[container.Name]
+	})
+	if !targetsContainers {
+		for _, container := range podSpec.Containers {
+			targeted[container.Name] = true
+			shared[container.Name] = true
+		}
+	}
+	return targeted, shared, nil
+}
+
+func hasContainer(podSpec v1.PodSpec, name string) bool {
+	return slices.ContainsFunc(slices.Concat(podSpec.InitContainers, podSpec.Containers), func(container v1.Container) bool {
+		return container.Name == name
+	})
+}
+
+// overrideContainer overrides the command and the args of the provided container, and returns true if the
+// command has been overridden. The overrides specific to the container take precedence over the ones shared
+// by all the targeted containers, which are applied only if shared is true.
+func (c PodConfigurator) overrideContainer(container *v1.Container, shared bool) bool {
+	var command, args []string
+	if shared {
+		command, args = c.options.Command, c.options.Args
+	}
+	override := c.options.ContainerOverrides[container.Name]
+	if override.Command != nil {
+		command, args = override.Command, nil
+	}
+	if override.Args != nil {
+		args = override.Args
+	}
+	if command == nil && override.Args == nil {
+		return false
+	}
+
+	container.Args = args
+	if command != nil {
+		container.Command = command
+	}
+	return command != nil
+}
+
+// overridePolicies applies the policies of the probes and of the lifecycle hooks to the provided container.
+// The replaced probes and hooks run the succeed command, or are removed if it's nil.
+func (c PodConfigurator) overridePolicies(
+	container *v1.Container,
+	targeted bool,
+	overridden bool,
+	succeed []string,
+) {
+	policy := func(policies core.OverridePolicies) core.OverridePolicy {
+		p := policies.For(container.Name, targeted)
+		if p != core.OverridePolicyAuto {
+			return p
+		}
+		if overridden {
+			return core.OverridePolicyRemove
+		}
+		return core.OverridePolicyKeep
+	}
+
+	container.LivenessProbe = overrideProbe(container.LivenessProbe, policy(c.options.LivenessProbe), succeed)
+	container.ReadinessProbe = overrideProbe(container.ReadinessProbe, policy(c.options.ReadinessProbe), succeed)
+	container.StartupProbe = overrideProbe(container.StartupProbe, policy(c.options.StartupProbe), succeed)
+	if container.Lifecycle != nil {
+		container.Lifecycle.PostStart = overrideHook(container.Lifecycle.PostStart, policy(c.options.PostStartHook), succeed)
+		container.Lifecycle.PreStop = overrideHook(container.Lifecycle.PreStop, policy(c.options.PreStopHook), succeed)
+	}
+}
+
+// alwaysSucceedCommand returns the command of the probes and of the hooks replaced by a trivial one.
+// The image may not have any binary (e.g. distroless), so the command is only available with the debug tools,
+// which provide a statically linked `true`. Otherwise, nil is returned and the replaced probes and hooks are
+// removed: a missing probe or hook behaves as one that always succeeds.
+func (c PodConfigurator) alwaysSucceedCommand() []string {
+	if !c.options.DebugTools {
+		return nil
+	}
+	return []string{path.Join(core.DEBUG_TOOLS_DIR, "true")}
+}
+
+func overrideProbe(probe *v1.Probe, policy core.OverridePolicy, alwaysSucceedCommand []string) *v1.Probe {
+	if probe == nil {
+		return nil
+	}
+	switch policy {
+	case core.OverridePolicyRemove:
+		return nil
+	case core.OverridePolicyReplace:
+		if alwaysSucceedCommand == nil {
+			return nil
+		}
+		// keep the timing settings of the original probe
+		replaced := probe.DeepCopy()
+		replaced.ProbeHandler = v1.ProbeHandler{
+			Exec: &v1.ExecAction{Command: alwaysSucceedCommand},
+		}
+		return replaced
+	default:
+		return probe
+	}
+}
+
+func overrideHook(hook *v1.LifecycleHandler, policy core.OverridePolicy, alwaysSucceedCommand []string) *v1.LifecycleHandler {
+	if hook == nil {
+		return nil
+	}
+	switch policy {
+	case core.OverridePolicyRemove:
+		return nil
+	case core.OverridePolicyReplace:
+		if alwaysSucceedCommand == nil {
+			return nil
+		}
+		return &v1.LifecycleHandler{
+			Exec: &v1.ExecAction{Command: alwaysSucceedCommand},
+		}
+	default:
+		return hook
+	}
+}
+
+// injectDebugTools adds an init container copying the debug tools into a volume shared
+// with the containers of the Pod. This allows to keep idle and to open a shell in images
+// that don't have one (e.g. distroless).
+// The tools are appended to the PATH of the idle containers, whose command has been overridden, so that
+// the idle command can run them. The PATH of the other containers is left alone, since the tools are added
+// to it when the shell session starts, and overriding it would change the environment of their original command.
+// The init container runs with the restricted Pod Security Standard, so it's admitted in any namespace.
+func injectDebugTools(podSpec *v1.PodSpec, image string, idle map[string]bool) {
+	if image == "" {
+		image = core.DEBUG_TOOLS_IMAGE
+	}
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name: core.DEBUG_TOOLS_VOLUME,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+	podSpec.InitContainers = append([]v1.Container{{
+		Name:  core.DEBUG_TOOLS_CONTAINER,
+		Image: image,
+		// the ownership of the binaries can't be preserved by a non-root user, and isn't needed
+		Command: []string{"/bin/sh", "-c", fmt.Sprintf("cp -dR /bin/. %s/", core.DEBUG_TOOLS_DIR)},
+		VolumeMounts: []v1.VolumeMount{{
+			Name:      core.DEBUG_TOOLS_VOLUME,
+			MountPath: core.DEBUG_TOOLS_DIR,
+		}},
+		SecurityContext: restrictedSecurityContext(),
+	}}, podSpec.InitContainers...)
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      core.DEBUG_TOOLS_VOLUME,
+			MountPath: core.DEBUG_TOOLS_DIR,
+			ReadOnly:  true,
+		})
+		if !idle[container.Name] {
+			continue
+		}
+		setPath := false
+		for j := range container.Env {
+			if container.Env[j].Name == "PATH" {
+				container.Env[j].Value = core.DebugToolsPath(container.Env[j].Value)
+				setPath = true
+			}
+		}
+		if !setPath {
+			container.Env = append(container.Env, v1.EnvVar{Name: "PATH", Value: core.DebugToolsPath("")})
+		}
+	}
+}
+
+// restrictedSecurityContext returns a security context satisfying the restricted Pod Security Standard.
+// The user is set explicitly, since the images providing the debug tools usually run as root.
+func restrictedSecurityContext() *v1.SecurityContext {
+	return &v1.SecurityContext{
+		RunAsNonRoot:             ptr.To(true),
+		RunAsUser:                ptr.To[int64](core.DEBUG_TOOLS_USER),
+		RunAsGroup:               ptr.To[int64](core.DEBUG_TOOLS_USER),
+		AllowPrivilegeEscalation: ptr.To(false),
+		ReadOnlyRootFilesystem:   ptr.To(true),
+		Capabilities: &v1.Capabilities{
+			Drop: []v1.Capability{"ALL"},
+		},
+		SeccompProfile: &v1.SeccompProfile{
+			Type: v1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
 func (c PodConfigurator) hasMountOncePvc(
 	ctx context.Context,
 	namespace string,
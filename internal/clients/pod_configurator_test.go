@@ -0,0 +1,335 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	v1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func Test_OverrideSpec_DebugTools(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{Name: "app", Image: "gcr.io/distroless/static"},
+			{Name: "worker", Image: "python", Env: []v1.EnvVar{{Name: "PATH", Value: "/usr/local/bin"}}},
+			{Name: "sidecar", Image: "envoy", Command: []string{"envoy"}},
+		},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		Command:    []string{"sh"},
+		Containers: []string{"app", "worker"},
+		DebugTools: true,
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+
+	assert.Len(t, podSpec.InitContainers, 1)
+	assert.Equal(t, core.DEBUG_TOOLS_CONTAINER, podSpec.InitContainers[0].Name)
+	assert.Equal(t, core.DEBUG_TOOLS_IMAGE, podSpec.InitContainers[0].Image)
+	// the init container is admitted by the restricted Pod Security Standard
+	securityContext := podSpec.InitContainers[0].SecurityContext
+	if assert.NotNil(t, securityContext) {
+		assert.True(t, *securityContext.RunAsNonRoot)
+		assert.NotZero(t, *securityContext.RunAsUser)
+		assert.False(t, *securityContext.AllowPrivilegeEscalation)
+		assert.Equal(t, []v1.Capability{"ALL"}, securityContext.Capabilities.Drop)
+		assert.Equal(t, v1.SeccompProfileTypeRuntimeDefault, securityContext.SeccompProfile.Type)
+	}
+	assert.Len(t, podSpec.Volumes, 1)
+	assert.Equal(t, core.DEBUG_TOOLS_VOLUME, podSpec.Volumes[0].Name)
+
+	// the tools are added to the PATH of the idle containers only
+	assert.Equal(t, []v1.EnvVar{{Name: "PATH", Value: core.DebugToolsPath("")}}, podSpec.Containers[0].Env)
+	assert.Equal(t, []v1.EnvVar{{Name: "PATH", Value: "/usr/local/bin:" + core.DEBUG_TOOLS_DIR}}, podSpec.Containers[1].Env)
+	assert.Empty(t, podSpec.Containers[2].Env)
+	assert.Equal(t, []string{"sh"}, podSpec.Containers[0].Command)
+	assert.Equal(t, []string{"sh"}, podSpec.Containers[1].Command)
+	assert.Equal(t, []string{"envoy"}, podSpec.Containers[2].Command)
+	for _, c := range podSpec.Containers {
+		assert.Equal(t, core.DEBUG_TOOLS_DIR, c.VolumeMounts[0].MountPath)
+	}
+}
+
+func Test_OverrideSpec_DebugToolsDisabled(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{{Name: "app", Image: "nginx"}},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+	assert.Empty(t, podSpec.InitContainers)
+	assert.Empty(t, podSpec.Volumes)
+	assert.Empty(t, podSpec.Containers[0].Env)
+}
+
+func Test_OverrideSpec_TargetedContainers(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{Name: "app", Command: []string{"/app"}, LivenessProbe: &v1.Probe{}},
+			{Name: "envoy", Command: []string{"envoy"}, LivenessProbe: &v1.Probe{}},
+			{Name: "vault-agent", Command: []string{"vault"}, Args: []string{"agent"}},
+		},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		Command:    []string{"sh"},
+		Args:       []string{"-c", "sleep infinity"},
+		Containers: []string{"app"},
+		ContainerOverrides: map[string]core.ContainerOverride{
+			"vault-agent": {Args: []string{"agent", "-log-level=debug"}},
+		},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"sh"}, podSpec.Containers[0].Command)
+	assert.Equal(t, []string{"-c", "sleep infinity"}, podSpec.Containers[0].Args)
+	assert.Nil(t, podSpec.Containers[0].LivenessProbe)
+	// untargeted containers keep their original behavior
+	assert.Equal(t, []string{"envoy"}, podSpec.Containers[1].Command)
+	assert.NotNil(t, podSpec.Containers[1].LivenessProbe)
+	// containers with specific overrides only get them
+	assert.Equal(t, []string{"vault"}, podSpec.Containers[2].Command)
+	assert.Equal(t, []string{"agent", "-log-level=debug"}, podSpec.Containers[2].Args)
+}
+
+func Test_OverrideSpec_ContainerCommand(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{Name: "app", Command: []string{"/app"}, Args: []string{"--port=8080"}},
+			{Name: "sidecar", Command: []string{"/sidecar"}},
+		},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		Command: []string{"sh"},
+		ContainerOverrides: map[string]core.ContainerOverride{
+			"app": {Command: []string{"/app", "--debug"}},
+		},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"/app", "--debug"}, podSpec.Containers[0].Command)
+	assert.Nil(t, podSpec.Containers[0].Args)
+	assert.Equal(t, []string{"/sidecar"}, podSpec.Containers[1].Command)
+}
+
+func Test_OverrideSpec_UnknownContainer(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{{Name: "app"}},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		Command:    []string{"sh"},
+		Containers: []string{"missing"},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.EqualError(t, err, `container "missing" not found`)
+}
+
+func Test_OverrideSpec_ProbePolicies(t *testing.T) {
+	probe := &v1.Probe{
+		ProbeHandler:  v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz"}},
+		PeriodSeconds: 10,
+	}
+	hook := &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"/drain"}}}
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{
+				Name:           "app",
+				Command:        []string{"/app"},
+				LivenessProbe:  probe.DeepCopy(),
+				ReadinessProbe: probe.DeepCopy(),
+				Lifecycle:      &v1.Lifecycle{PreStop: hook.DeepCopy()},
+			},
+			{
+				Name:          "envoy",
+				Command:       []string{"envoy"},
+				LivenessProbe: probe.DeepCopy(),
+			},
+		},
+	}
+	// keep the original command, only remove the liveness probes and replace the readiness ones
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		LivenessProbe:  core.OverridePolicies{"": core.OverridePolicyRemove, "envoy": core.OverridePolicyKeep},
+		ReadinessProbe: core.OverridePolicies{"": core.OverridePolicyReplace},
+		PreStopHook:    core.OverridePolicies{"app": core.OverridePolicyRemove},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+
+	app := podSpec.Containers[0]
+	assert.Equal(t, []string{"/app"}, app.Command)
+	assert.Nil(t, app.LivenessProbe)
+	// without the debug tools, the image may have no binary to run, so the replaced probes are removed
+	assert.Nil(t, app.ReadinessProbe)
+	assert.Nil(t, app.Lifecycle.PreStop)
+	assert.Equal(t, probe, podSpec.Containers[1].LivenessProbe)
+}
+
+func Test_OverrideSpec_ReplacedWithDebugTools(t *testing.T) {
+	probe := &v1.Probe{
+		ProbeHandler:  v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz"}},
+		PeriodSeconds: 10,
+	}
+	hook := &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"/drain"}}}
+	always := v1.ContainerRestartPolicyAlways
+	podSpec := v1.PodSpec{
+		InitContainers: []v1.Container{
+			{Name: "istio-proxy", RestartPolicy: &always, ReadinessProbe: probe.DeepCopy()},
+		},
+		Containers: []v1.Container{
+			{
+				Name:           "app",
+				ReadinessProbe: probe.DeepCopy(),
+				Lifecycle:      &v1.Lifecycle{PreStop: hook.DeepCopy()},
+			},
+		},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		DebugTools:     true,
+		ReadinessProbe: core.OverridePolicies{"": core.OverridePolicyReplace, "istio-proxy": core.OverridePolicyReplace},
+		PreStopHook:    core.OverridePolicies{"": core.OverridePolicyReplace},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+
+	// the debug tools provide a statically linked true, keeping the timing settings of the probes
+	app := podSpec.Containers[0]
+	assert.Equal(t, []string{"/duplik8s-tools/true"}, app.ReadinessProbe.Exec.Command)
+	assert.Nil(t, app.ReadinessProbe.HTTPGet)
+	assert.Equal(t, int32(10), app.ReadinessProbe.PeriodSeconds)
+	assert.Equal(t, []string{"/duplik8s-tools/true"}, app.Lifecycle.PreStop.Exec.Command)
+	// the debug tools are not mounted in the native sidecars
+	assert.Equal(t, "istio-proxy", podSpec.InitContainers[1].Name)
+	assert.Nil(t, podSpec.InitContainers[1].ReadinessProbe)
+}
+
+func Test_OverrideSpec_ProbesRemovedWithCommand(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{Name: "app", LivenessProbe: &v1.Probe{}, ReadinessProbe: &v1.Probe{}, StartupProbe: &v1.Probe{}},
+		},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		Command:       []string{"sh"},
+		StartupProbe:  core.OverridePolicies{"app": core.OverridePolicyKeep},
+		PostStartHook: core.OverridePolicies{"missing": core.OverridePolicyKeep},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.EqualError(t, err, `container "missing" not found`)
+
+	configurator = NewConfigurator(nil, core.DuplicateOpts{
+		Command:      []string{"sh"},
+		StartupProbe: core.OverridePolicies{"app": core.OverridePolicyKeep},
+	})
+	err = configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+	assert.Nil(t, podSpec.Containers[0].LivenessProbe)
+	assert.Nil(t, podSpec.Containers[0].ReadinessProbe)
+	assert.NotNil(t, podSpec.Containers[0].StartupProbe)
+}
+
+func Test_OverrideSpec_NativeSidecars(t *testing.T) {
+	always := v1.ContainerRestartPolicyAlways
+	podSpec := v1.PodSpec{
+		InitContainers: []v1.Container{
+			{Name: "migrate", Command: []string{"/migrate"}},
+			{Name: "istio-proxy", Command: []string{"pilot-agent"}, RestartPolicy: &always, LivenessProbe: &v1.Probe{}},
+			{Name: "vault-agent", Command: []string{"vault"}, RestartPolicy: &always},
+			{Name: "config", Command: []string{"/render-config"}},
+		},
+		Containers: []v1.Container{{Name: "app", Command: []string{"/app"}}},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		Command:            []string{"sh"},
+		KeepInitContainers: []string{"config"},
+		DropInitContainers: []string{"vault-agent"},
+		ContainerOverrides: map[string]core.ContainerOverride{
+			"config": {Args: []string{"--dry-run"}},
+		},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+
+	assert.Len(t, podSpec.InitContainers, 2)
+	// native sidecars are kept untouched
+	assert.Equal(t, "istio-proxy", podSpec.InitContainers[0].Name)
+	assert.Equal(t, []string{"pilot-agent"}, podSpec.InitContainers[0].Command)
+	assert.NotNil(t, podSpec.InitContainers[0].LivenessProbe)
+	// init containers get their own overrides
+	assert.Equal(t, "config", podSpec.InitContainers[1].Name)
+	assert.Equal(t, []string{"/render-config"}, podSpec.InitContainers[1].Command)
+	assert.Equal(t, []string{"--dry-run"}, podSpec.InitContainers[1].Args)
+	// overriding init containers doesn't prevent the shared overrides
+	assert.Equal(t, []string{"sh"}, podSpec.Containers[0].Command)
+}
+
+func Test_OverrideSpec_TargetedInitContainers(t *testing.T) {
+	always := v1.ContainerRestartPolicyAlways
+	hook := &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"/drain"}}}
+	podSpec := v1.PodSpec{
+		InitContainers: []v1.Container{
+			{
+				Name:          "istio-proxy",
+				Command:       []string{"pilot-agent"},
+				RestartPolicy: &always,
+				LivenessProbe: &v1.Probe{},
+			},
+			{
+				Name:      "migrate",
+				Command:   []string{"/migrate"},
+				Lifecycle: &v1.Lifecycle{PostStart: hook.DeepCopy()},
+			},
+		},
+		Containers: []v1.Container{{Name: "app", Command: []string{"/app"}}},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		Command:                []string{"sh"},
+		Args:                   []string{"-c", "sleep infinity"},
+		Containers:             []string{"istio-proxy", "migrate"},
+		PreserveInitContainers: true,
+		PostStartHook:          core.OverridePolicies{"": core.OverridePolicyRemove},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.NoError(t, err)
+
+	// native sidecars get the shared overrides, and their probes are removed with the command
+	sidecar := podSpec.InitContainers[0]
+	assert.Equal(t, []string{"sh"}, sidecar.Command)
+	assert.Equal(t, []string{"-c", "sleep infinity"}, sidecar.Args)
+	assert.Nil(t, sidecar.LivenessProbe)
+	// the other init containers keep their command, so that they complete, but get the policies
+	migrate := podSpec.InitContainers[1]
+	assert.Equal(t, []string{"/migrate"}, migrate.Command)
+	assert.Nil(t, migrate.Args)
+	assert.Nil(t, migrate.Lifecycle.PostStart)
+	// no container other than init containers has been targeted, so all of them are
+	assert.Equal(t, []string{"sh"}, podSpec.Containers[0].Command)
+}
+
+func Test_OverrideSpec_UnknownInitContainer(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{{Name: "app"}},
+	}
+	configurator := NewConfigurator(nil, core.DuplicateOpts{
+		KeepInitContainers: []string{"migrate"},
+	})
+	err := configurator.OverrideSpec(context.Background(), "default", &podSpec)
+	assert.EqualError(t, err, `init container "migrate" not found`)
+}
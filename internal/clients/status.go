@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// objectStatus returns a short description of the state of the provided object: the phase of Pods,
+// the ready replicas of workloads and the completion of Jobs. If unknown, an empty string is returned.
+func objectStatus(u *unstructured.Unstructured) string {
+	if u.GetDeletionTimestamp() != nil {
+		return "Terminating"
+	}
+	if phase, found, _ := unstructured.NestedString(u.Object, "status", "phase"); found {
+		return phase
+	}
+	if replicas, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas"); found {
+		ready, _, _ := unstructured.NestedInt64(u.Object, "status", "readyReplicas")
+		return fmt.Sprintf("%d/%d ready", ready, replicas)
+	}
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok || condition["status"] != "True" {
+			continue
+		}
+		// conditions marking the end of Jobs
+		if t := condition["type"]; t == "Complete" || t == "Failed" {
+			return t.(string)
+		}
+	}
+	if active, found, _ := unstructured.NestedInt64(u.Object, "status", "active"); found && active > 0 {
+		return "Running"
+	}
+	return ""
+}
+
+// objectNode returns the node the provided object is scheduled on, if it's a Pod, or an empty string otherwise.
+func objectNode(u *unstructured.Unstructured) string {
+	node, _, _ := unstructured.NestedString(u.Object, "spec", "nodeName")
+	return node
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+	"time"
+)
+
+func Test_ObjectStatus(t *testing.T) {
+	terminating := &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"phase": "Running"}}}
+	terminating.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+
+	testCases := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected string
+	}{
+		{
+			name:     "pod",
+			obj:      &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"phase": "Pending"}}},
+			expected: "Pending",
+		},
+		{
+			name:     "terminating",
+			obj:      terminating,
+			expected: "Terminating",
+		},
+		{
+			name: "deployment",
+			obj: &unstructured.Unstructured{Object: map[string]any{
+				"spec":   map[string]any{"replicas": int64(2)},
+				"status": map[string]any{"readyReplicas": int64(1)},
+			}},
+			expected: "1/2 ready",
+		},
+		{
+			name: "completed job",
+			obj: &unstructured.Unstructured{Object: map[string]any{
+				"status": map[string]any{"conditions": []any{
+					map[string]any{"type": "SuccessCriteriaMet", "status": "True"},
+					map[string]any{"type": "Complete", "status": "True"},
+				}},
+			}},
+			expected: "Complete",
+		},
+		{
+			name:     "running job",
+			obj:      &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"active": int64(1)}}},
+			expected: "Running",
+		},
+		{
+			name:     "unknown",
+			obj:      &unstructured.Unstructured{Object: map[string]any{}},
+			expected: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, objectStatus(tc.obj))
+		})
+	}
+}
@@ -18,64 +18,291 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
 	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/cmd/flags"
 	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"path"
+	"slices"
+	"strings"
+	"time"
 )
 
-func cleanup(client core.Client, namespace string) error {
+// cleanupFilters selects the duplicated resources to cleanup. Empty filters select all the resources.
+type cleanupFilters struct {
+	// Expired selects the resources whose TTL has expired.
+	Expired bool
+	// Abandoned selects the resources whose shell session has been abandoned.
+	Abandoned bool
+	// Kinds selects the resources of the provided kinds, case-insensitive.
+	Kinds []string
+	// OlderThan selects the resources older than the provided age.
+	OlderThan time.Duration
+	// Selector selects the resources whose labels match it.
+	Selector labels.Selector
+	// Names selects the resources whose name matches one of the provided glob patterns.
+	Names []string
+	// Owner selects the resources created by the provided user.
+	Owner string
+}
+
+// matches returns true if the provided object is selected by all the filters at the provided time.
+// Expired and Abandoned select the union of the expired and abandoned resources.
+func (f cleanupFilters) matches(obj core.DuplicatedObject, now time.Time) bool {
+	if (f.Expired || f.Abandoned) && !(f.Expired && obj.Expired(now)) && !(f.Abandoned && obj.Abandoned(now)) {
+		return false
+	}
+	kind := obj.ObjectKind.GroupVersionKind().Kind
+	if len(f.Kinds) > 0 && !slices.ContainsFunc(f.Kinds, func(k string) bool { return strings.EqualFold(k, kind) }) {
+		return false
+	}
+	if f.OlderThan > 0 && now.Sub(obj.CreationTimestamp.Time) <= f.OlderThan {
+		return false
+	}
+	if f.Selector != nil && !f.Selector.Matches(labels.Set(obj.Labels)) {
+		return false
+	}
+	if len(f.Names) > 0 && !slices.ContainsFunc(f.Names, func(pattern string) bool {
+		matched, _ := path.Match(pattern, obj.Name)
+		return matched
+	}) {
+		return false
+	}
+	return ownedBy(obj, f.Owner)
+}
+
+// empty returns true if no filter other than the owner is set.
+func (f cleanupFilters) empty() bool {
+	return !f.Expired && !f.Abandoned && len(f.Kinds) == 0 && f.OlderThan == 0 && f.Selector == nil && len(f.Names) == 0
+}
+
+type cleanupOptions struct {
+	Filters cleanupFilters
+	// Yes indicates whether to delete the selected resources without prompting.
+	Yes bool
+	// DryRun indicates whether to only print the resources that would be deleted.
+	DryRun bool
+	Delete core.DeleteOpts
+}
+
+// cleanup deletes the duplicated resources selected by the filters. Deletion errors don't stop the cleanup:
+// they are reported all together once every resource has been processed.
+func cleanup(client core.Client, namespace string, opts cleanupOptions) error {
 	duplicated, err := client.ListDuplicated(context.Background(), namespace)
 	if err != nil {
 		return err
 	}
+	now := time.Now()
+	duplicated = slices.DeleteFunc(duplicated, func(obj core.DuplicatedObject) bool {
+		return !opts.Filters.matches(obj, now)
+	})
 	if len(duplicated) == 0 {
-		fmt.Printf("No duplicated resources found in namespace %q\n", namespace)
+		switch {
+		case opts.Filters.empty() && opts.Filters.Owner == "":
+			fmt.Printf("No duplicated resources found in namespace %q\n", namespace)
+		case opts.Filters.empty():
+			fmt.Printf(
+				"No duplicated resources created by %q found in namespace %q, use --%s to cleanup the ones of every user\n",
+				opts.Filters.Owner,
+				namespace,
+				flags.ALL_OWNERS,
+			)
+		default:
+			fmt.Printf("No duplicated resources matching the filters found in namespace %q\n", namespace)
+		}
 		return nil
 	}
 
 	renderDuplicatedObjects(duplicated)
 
-	var shouldDelete bool
-	err = huh.NewConfirm().Title("Do you want to delete the following resources?").Value(&shouldDelete).Run()
-	if err != nil {
-		return err
+	if opts.DryRun {
+		for _, obj := range duplicated {
+			fmt.Printf("%s %s/%s would be deleted (dry run)\n", obj.ObjectKind.GroupVersionKind().Kind, obj.Namespace, obj.Name)
+		}
+		return nil
+	}
+	if !opts.Yes {
+		duplicated, err = utils.SelectDuplicated(duplicated, "Select the resources to delete")
+		if err != nil {
+			return err
+		}
 	}
 
-	if shouldDelete {
-		for _, obj := range duplicated {
-			err = client.Delete(context.Background(), obj)
-			if err != nil {
-				return err
-			}
-			fmt.Printf("deleted %s %s/%s\n", obj.ObjectKind.GroupVersionKind().Kind, obj.Namespace, obj.Name)
+	var errs []error
+	for _, obj := range duplicated {
+		kind := obj.ObjectKind.GroupVersionKind().Kind
+		err = client.Delete(context.Background(), obj, opts.Delete)
+		var finalizersErr *core.FinalizersError
+		if !opts.Delete.Wait && errors.As(err, &finalizersErr) {
+			fmt.Printf("deleted %s %s/%s, %s\n", kind, obj.Namespace, obj.Name, finalizersErr)
+			continue
 		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s/%s: %w", kind, obj.Namespace, obj.Name, err))
+			continue
+		}
+		fmt.Printf("deleted %s %s/%s\n", kind, obj.Namespace, obj.Name)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf(
+			"%d of %d resources could not be deleted:\n%w",
+			len(errs),
+			len(duplicated),
+			errors.Join(errs...),
+		)
 	}
 
 	return nil
 }
 
+func newCleanupOptions(cmd *cobra.Command) (cleanupOptions, error) {
+	var opts cleanupOptions
+	var err error
+	opts.Filters.Expired, err = cmd.Flags().GetBool(flags.EXPIRED)
+	if err != nil {
+		return opts, err
+	}
+	opts.Filters.Abandoned, err = cmd.Flags().GetBool(flags.ABANDONED)
+	if err != nil {
+		return opts, err
+	}
+	opts.Filters.Kinds, err = cmd.Flags().GetStringSlice(flags.KIND)
+	if err != nil {
+		return opts, err
+	}
+	opts.Filters.OlderThan, err = cmd.Flags().GetDuration(flags.OLDER_THAN)
+	if err != nil {
+		return opts, err
+	}
+	selector, err := cmd.Flags().GetString(flags.SELECTOR)
+	if err != nil {
+		return opts, err
+	}
+	if selector != "" {
+		opts.Filters.Selector, err = labels.Parse(selector)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --%s: %w", flags.SELECTOR, err)
+		}
+	}
+	opts.Filters.Names, err = cmd.Flags().GetStringSlice(flags.NAME)
+	if err != nil {
+		return opts, err
+	}
+	for _, pattern := range opts.Filters.Names {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return opts, fmt.Errorf("invalid --%s %q: %w", flags.NAME, pattern, err)
+		}
+	}
+	opts.Yes, err = cmd.Flags().GetBool(flags.YES)
+	if err != nil {
+		return opts, err
+	}
+	opts.DryRun, err = cmd.Flags().GetBool(flags.DRY_RUN)
+	if err != nil {
+		return opts, err
+	}
+	cascade, err := cmd.Flags().GetString(flags.CASCADE)
+	if err != nil {
+		return opts, err
+	}
+	opts.Delete.PropagationPolicy, err = parseCascade(cascade)
+	if err != nil {
+		return opts, err
+	}
+	opts.Delete.Wait, err = cmd.Flags().GetBool(flags.WAIT)
+	if err != nil {
+		return opts, err
+	}
+	opts.Delete.WaitTimeout, err = cmd.Flags().GetDuration(flags.WAIT_TIMEOUT)
+	if err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// parseCascade returns the propagation policy corresponding to the provided --cascade value.
+func parseCascade(value string) (metav1.DeletionPropagation, error) {
+	switch strings.ToLower(value) {
+	case "background":
+		return metav1.DeletePropagationBackground, nil
+	case "foreground":
+		return metav1.DeletePropagationForeground, nil
+	case "orphan":
+		return metav1.DeletePropagationOrphan, nil
+	default:
+		return "", fmt.Errorf("invalid --%s %q: must be one of background, foreground or orphan", flags.CASCADE, value)
+	}
+}
+
 func NewCleanupCmd(client core.Client) *cobra.Command {
 	podCmd := &cobra.Command{
 		Use:   "cleanup",
 		Short: "Cleanup duplicated resources.",
-		Args:  cobra.MaximumNArgs(1),
+		Long: "Cleanup the duplicated resources matching all the provided filters. Unless --yes or --dry-run " +
+			"are provided, the resources to delete are selected interactively.\n\n" +
+			"By default, only the duplicated resources created by the current user are cleaned up. " +
+			"Use --owner for cleaning up the ones of another user, or --all-owners for the ones of every user, " +
+			"including the ones whose creator is unknown.",
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
 			opts, err := NewKubeOptions(cmd, args)
 			if err != nil {
 				return err
 			}
+			cleanupOpts, err := newCleanupOptions(cmd)
+			if err != nil {
+				return err
+			}
 			if client == nil {
 				client, err = clients.NewDuplik8sClient(opts)
 				if err != nil {
 					return err
 				}
 			}
-			return cleanup(client, opts.Namespace)
+			cleanupOpts.Filters.Owner, err = newOwnerFilter(cmd, client)
+			if err != nil {
+				return err
+			}
+			return cleanup(client, opts.Namespace, cleanupOpts)
 		},
 	}
-	addOverrideFlags(podCmd)
+	podCmd.Flags().Bool(flags.EXPIRED, false, "Only cleanup the duplicated resources whose TTL has expired.")
+	podCmd.Flags().Bool(
+		flags.ABANDONED,
+		false,
+		"Only cleanup the duplicated resources whose shell session has been abandoned, e.g. because the terminal died.",
+	)
+	podCmd.Flags().StringSlice(flags.KIND, nil, "Only cleanup the duplicated resources of the provided kinds (e.g. pod).")
+	podCmd.Flags().Duration(flags.OLDER_THAN, 0, "Only cleanup the duplicated resources older than the provided age.")
+	podCmd.Flags().StringP(
+		flags.SELECTOR,
+		"l",
+		"",
+		"Only cleanup the duplicated resources matching the label selector (e.g. app=nginx).",
+	)
+	podCmd.Flags().StringSlice(
+		flags.NAME,
+		nil,
+		"Only cleanup the duplicated resources whose name matches one of the provided glob patterns (e.g. nginx-*).",
+	)
+	podCmd.Flags().Bool(flags.MINE, true, "Only cleanup the duplicated resources created by the current user.")
+	podCmd.Flags().String(flags.OWNER, "", "Only cleanup the duplicated resources created by the provided user.")
+	podCmd.Flags().Bool(flags.ALL_OWNERS, false, "Cleanup the duplicated resources created by every user.")
+	podCmd.MarkFlagsMutuallyExclusive(flags.MINE, flags.OWNER, flags.ALL_OWNERS)
+	podCmd.Flags().BoolP(flags.YES, "y", false, "Delete the duplicated resources without prompting.")
+	podCmd.Flags().Bool(flags.DRY_RUN, false, "Only print the duplicated resources that would be deleted.")
+	podCmd.Flags().String(
+		flags.CASCADE,
+		"background",
+		"How the dependents of the duplicated resources (e.g. Pods) are deleted: background, foreground or orphan.",
+	)
+	podCmd.Flags().Bool(flags.WAIT, false, "Wait until the duplicated resources and their Pods are gone.")
+	podCmd.Flags().Duration(flags.WAIT_TIMEOUT, 2*time.Minute, "Maximum time to wait for each deletion with --wait.")
 	return podCmd
 }
@@ -0,0 +1,251 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/test"
+	"github.com/telemaco019/duplik8s/internal/test/mocks"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+	"time"
+)
+
+func Test_CleanupExpired_NothingExpired(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	expiresAt := metav1.NewTime(time.Now().Add(time.Hour))
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		{Name: "pod-1-duplik8ted", Namespace: "default", ExpiresAt: &expiresAt},
+		{Name: "pod-2-duplik8ted", Namespace: "default"},
+	}
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--expired")
+	assert.NoError(t, err)
+}
+
+func Test_CleanupExpired(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	expired := newDuplicatedObject("expired-duplik8ted", "Pod", 2*time.Hour, nil)
+	expired.ExpiresAt = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	alive := newDuplicatedObject("alive-duplik8ted", "Pod", 2*time.Hour, nil)
+	alive.ExpiresAt = &metav1.Time{Time: time.Now().Add(time.Hour)}
+	noTTL := newDuplicatedObject("no-ttl-duplik8ted", "Pod", 2*time.Hour, nil)
+	client.ListDuplicatedResult = []core.DuplicatedObject{expired, alive, noTTL}
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--expired", "--yes")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"expired-duplik8ted"}, deletedNames(client))
+}
+
+func Test_CleanupAbandoned_NothingAbandoned(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	sessionExpiresAt := metav1.NewTime(time.Now().Add(time.Minute))
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		{Name: "pod-1-duplik8ted", Namespace: "default", SessionExpiresAt: &sessionExpiresAt},
+		{Name: "pod-2-duplik8ted", Namespace: "default"},
+	}
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--abandoned")
+	assert.NoError(t, err)
+}
+
+func newDuplicatedObject(name, kind string, age time.Duration, labels map[string]string) core.DuplicatedObject {
+	obj := core.DuplicatedObject{
+		Name:              name,
+		Namespace:         "default",
+		ObjectKind:        &metav1.TypeMeta{},
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		Labels:            labels,
+		Creator:           mocks.CurrentUser,
+	}
+	obj.ObjectKind.SetGroupVersionKind(schema.GroupVersionKind{Kind: kind})
+	return obj
+}
+
+func deletedNames(client *mocks.PodClient) []string {
+	names := make([]string, 0, len(client.Deleted))
+	for _, obj := range client.Deleted {
+		names = append(names, obj.Name)
+	}
+	return names
+}
+
+func Test_Cleanup_Filters(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "no filters", args: nil, expected: []string{"nginx-duplik8ted", "api-duplik8ted", "old-duplik8ted"}},
+		{name: "kind", args: []string{"--kind", "pod"}, expected: []string{"nginx-duplik8ted", "old-duplik8ted"}},
+		{name: "older than", args: []string{"--older-than", "24h"}, expected: []string{"old-duplik8ted"}},
+		{
+			name:     "selector",
+			args:     []string{"-l", "app in (nginx,api)"},
+			expected: []string{"nginx-duplik8ted", "api-duplik8ted"},
+		},
+		{name: "name", args: []string{"--name", "nginx-*,old-*"}, expected: []string{"nginx-duplik8ted", "old-duplik8ted"}},
+		{
+			name:     "all filters",
+			args:     []string{"--kind", "Pod", "-l", "app", "--name", "*-duplik8ted"},
+			expected: []string{"nginx-duplik8ted"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+			client.ListDuplicatedResult = []core.DuplicatedObject{
+				newDuplicatedObject("nginx-duplik8ted", "Pod", time.Hour, map[string]string{"app": "nginx"}),
+				newDuplicatedObject("api-duplik8ted", "Deployment", time.Hour, map[string]string{"app": "api"}),
+				newDuplicatedObject("old-duplik8ted", "Pod", 48*time.Hour, nil),
+			}
+			cmd := NewRootCmd(client, client)
+			_, err := test.ExecuteCommand(cmd, append([]string{"cleanup", "--yes"}, tc.args...)...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, deletedNames(client))
+		})
+	}
+}
+
+func Test_Cleanup_DryRun(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		newDuplicatedObject("nginx-duplik8ted", "Pod", time.Hour, nil),
+	}
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--dry-run")
+	assert.NoError(t, err)
+	assert.Empty(t, client.Deleted)
+}
+
+func Test_Cleanup_ContinuesPastFailures(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		newDuplicatedObject("pod-1-duplik8ted", "Pod", time.Hour, nil),
+		newDuplicatedObject("pod-2-duplik8ted", "Pod", time.Hour, nil),
+		newDuplicatedObject("pod-3-duplik8ted", "Pod", time.Hour, nil),
+	}
+	client.DeleteErrors = map[string]error{
+		"pod-1-duplik8ted": errors.New("forbidden"),
+		"pod-3-duplik8ted": errors.New("timeout"),
+	}
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--yes")
+	assert.ErrorContains(t, err, "2 of 3 resources could not be deleted")
+	assert.ErrorContains(t, err, "failed to delete Pod default/pod-1-duplik8ted: forbidden")
+	assert.ErrorContains(t, err, "failed to delete Pod default/pod-3-duplik8ted: timeout")
+	assert.Equal(t, []string{"pod-2-duplik8ted"}, deletedNames(client))
+}
+
+func Test_Cleanup_InvalidSelector(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--yes", "-l", "app in (")
+	assert.ErrorContains(t, err, "invalid --selector")
+}
+
+func Test_Cleanup_Cascade(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		newDuplicatedObject("nginx-duplik8ted", "Deployment", time.Hour, nil),
+	}
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--yes", "--cascade", "Orphan", "--wait", "--wait-timeout", "10s")
+	assert.NoError(t, err)
+	assert.Equal(t, core.DeleteOpts{
+		PropagationPolicy: metav1.DeletePropagationOrphan,
+		Wait:              true,
+		WaitTimeout:       10 * time.Second,
+	}, client.DeleteOpts)
+
+	_, err = test.ExecuteCommand(cmd, "cleanup", "--yes", "--cascade", "cascade")
+	assert.ErrorContains(t, err, "invalid --cascade")
+}
+
+func Test_Cleanup_PendingFinalizers(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		newDuplicatedObject("nginx-duplik8ted", "Deployment", time.Hour, nil),
+	}
+	client.DeleteErrors = map[string]error{
+		"nginx-duplik8ted": &core.FinalizersError{Finalizers: []string{"example.com/protect"}},
+	}
+	cmd := NewRootCmd(client, client)
+	// without --wait, a deletion pending on finalizers is not a failure
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--yes")
+	assert.NoError(t, err)
+
+	_, err = test.ExecuteCommand(cmd, "cleanup", "--yes", "--wait")
+	assert.ErrorContains(t, err, "deletion blocked by finalizers [example.com/protect]")
+}
+
+func Test_Cleanup_Owner(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		// the resources whose creator is unknown may belong to other users
+		{name: "mine by default", args: nil, expected: []string{"nginx-duplik8ted"}},
+		{name: "owner", args: []string{"--owner", "bob"}, expected: []string{"api-duplik8ted"}},
+		{
+			name:     "all owners",
+			args:     []string{"--all-owners"},
+			expected: []string{"nginx-duplik8ted", "api-duplik8ted", "legacy-duplik8ted"},
+		},
+		{
+			name:     "not mine",
+			args:     []string{"--mine=false"},
+			expected: []string{"nginx-duplik8ted", "api-duplik8ted", "legacy-duplik8ted"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+			api := newDuplicatedObject("api-duplik8ted", "Deployment", time.Hour, nil)
+			api.Creator = "bob"
+			// duplicated without recording the creator
+			legacy := newDuplicatedObject("legacy-duplik8ted", "Pod", time.Hour, nil)
+			legacy.Creator = ""
+			client.ListDuplicatedResult = []core.DuplicatedObject{
+				newDuplicatedObject("nginx-duplik8ted", "Pod", time.Hour, nil),
+				api,
+				legacy,
+			}
+			cmd := NewRootCmd(client, client)
+			_, err := test.ExecuteCommand(cmd, append([]string{"cleanup", "--yes"}, tc.args...)...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, deletedNames(client))
+		})
+	}
+}
+
+func Test_Cleanup_UnknownCurrentUser(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	client.User = ""
+	client.ListDuplicatedResult = []core.DuplicatedObject{newDuplicatedObject("nginx-duplik8ted", "Pod", time.Hour, nil)}
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "cleanup", "--yes")
+	assert.ErrorContains(t, err, "use --owner")
+	assert.Empty(t, client.Deleted)
+
+	_, err = test.ExecuteCommand(NewRootCmd(client, client), "cleanup", "--yes", "--all-owners", "--owner", "bob")
+	assert.ErrorContains(t, err, "none of the others can be")
+}
@@ -17,8 +17,11 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/telemaco019/duplik8s/internal/cmd/flags"
+	"github.com/telemaco019/duplik8s/internal/core"
 	"github.com/telemaco019/duplik8s/internal/utils"
 )
 
@@ -41,3 +44,34 @@ func NewKubeOptions(cmd *cobra.Command, _ []string) (utils.KubeOptions, error) {
 
 	return o, nil
 }
+
+// newOwnerFilter returns the user whose duplicated resources are selected by the --owner, --mine and
+// --all-owners flags of the provided command, or an empty string if the resources of every user are selected.
+func newOwnerFilter(cmd *cobra.Command, client core.Client) (string, error) {
+	owner, err := cmd.Flags().GetString(flags.OWNER)
+	if err != nil || owner != "" {
+		return owner, err
+	}
+	if cmd.Flags().Lookup(flags.ALL_OWNERS) != nil {
+		allOwners, err := cmd.Flags().GetBool(flags.ALL_OWNERS)
+		if err != nil || allOwners {
+			return "", err
+		}
+	}
+	mine, err := cmd.Flags().GetBool(flags.MINE)
+	if err != nil || !mine {
+		return "", err
+	}
+	user, err := client.CurrentUser(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("%w, use --%s for selecting the resources of a specific user", err, flags.OWNER)
+	}
+	return user, nil
+}
+
+// ownedBy returns true if the provided object is selected by the owner filter. The resources whose creator
+// is unknown, e.g. because they were duplicated by an older version, may belong to any user, so they are
+// only selected when the resources of every user are.
+func ownedBy(obj core.DuplicatedObject, owner string) bool {
+	return owner == "" || obj.Creator == owner
+}
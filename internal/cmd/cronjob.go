@@ -0,0 +1,56 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/telemaco019/duplik8s/internal/cmd/flags"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/duplicators"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func NewCronJobCmd(duplicator core.Duplicator, client core.Client) *cobra.Command {
+	cronJobCmd := &cobra.Command{
+		Use:     "cronjob",
+		Aliases: []string{"cronjobs", "cj"},
+		Short:   "Duplicate a CronJob, running its Job template as a one-off Job.",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rerun, err := cmd.Flags().GetBool(flags.RERUN)
+			if err != nil {
+				return err
+			}
+			factory := func(opts utils.KubeOptions) (core.Duplicator, error) {
+				if duplicator == nil {
+					return duplicators.NewCronJobClient(opts, rerun)
+				}
+				return duplicator, nil
+			}
+			run := newDuplicateCmd(factory, client, schema.GroupVersionResource{
+				Group:    "batch",
+				Version:  "v1",
+				Resource: "cronjobs",
+			})
+			return run(cmd, args)
+		},
+	}
+	addOverrideFlags(cronJobCmd)
+	addJobFlags(cronJobCmd)
+	return cronJobCmd
+}
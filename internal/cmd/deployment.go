@@ -46,5 +46,6 @@ func NewDeployCmd(duplicator core.Duplicator, client core.Client) *cobra.Command
 		},
 	}
 	addOverrideFlags(deployCmd)
+	addDryRunFlags(deployCmd)
 	return deployCmd
 }
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	"io"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// findDuplicated returns the duplicated resource referenced by [KIND/]NAME, where KIND is case-insensitive
+// and can be qualified with its group (e.g. deployment.apps/nginx-duplik8ted).
+func findDuplicated(ctx context.Context, client core.Client, namespace, ref string) (core.DuplicatedObject, error) {
+	kind, name, found := strings.Cut(ref, "/")
+	if !found {
+		kind, name = "", ref
+	}
+	duplicated, err := client.ListDuplicated(ctx, namespace)
+	if err != nil {
+		return core.DuplicatedObject{}, err
+	}
+	var matches []core.DuplicatedObject
+	for _, obj := range duplicated {
+		gvk := obj.ObjectKind.GroupVersionKind()
+		if obj.Name != name {
+			continue
+		}
+		if kind != "" && !strings.EqualFold(kind, gvk.Kind) && !strings.EqualFold(kind, gvk.GroupKind().String()) {
+			continue
+		}
+		matches = append(matches, obj)
+	}
+	switch len(matches) {
+	case 0:
+		return core.DuplicatedObject{}, fmt.Errorf("duplicated resource %q not found in namespace %q", ref, namespace)
+	case 1:
+		return matches[0], nil
+	}
+	refs := make([]string, 0, len(matches))
+	for _, obj := range matches {
+		refs = append(refs, strings.ToLower(obj.ObjectKind.GroupVersionKind().GroupKind().String())+"/"+obj.Name)
+	}
+	return core.DuplicatedObject{}, fmt.Errorf(
+		"%q matches more than one duplicated resource, use KIND/NAME: %s",
+		ref,
+		strings.Join(refs, ", "),
+	)
+}
+
+// getSource returns the current version of the source of the provided duplicated resource,
+// or nil if it's unknown or has been deleted.
+func getSource(ctx context.Context, client core.Client, obj core.DuplicatedObject) (*unstructured.Unstructured, error) {
+	if obj.Source == nil {
+		return nil, nil
+	}
+	source, err := client.Get(ctx, obj.Source.GroupVersionKind(), obj.Namespace, obj.Source.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// a resource with the same name created after the duplication is not the source
+	if obj.Source.UID != "" && source.GetUID() != obj.Source.UID {
+		return nil, nil
+	}
+	return source, nil
+}
+
+func describe(ctx context.Context, w io.Writer, client core.Client, obj core.DuplicatedObject) error {
+	duplicate, err := client.Get(ctx, obj.ObjectKind.GroupVersionKind(), obj.Namespace, obj.Name)
+	if err != nil {
+		return err
+	}
+	source, err := getSource(ctx, client, obj)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Name:       %s\n", obj.Name)
+	fmt.Fprintf(w, "Namespace:  %s\n", obj.Namespace)
+	fmt.Fprintf(w, "Kind:       %s\n", obj.ObjectKind.GroupVersionKind().Kind)
+	fmt.Fprintf(w, "Age:        %s\n", utils.FormatAge(obj.CreationTimestamp))
+	fmt.Fprintf(w, "TTL:        %s\n", utils.FormatTTL(obj.ExpiresAt))
+	fmt.Fprintf(w, "Status:     %s\n", orDash(obj.Status))
+	fmt.Fprintf(w, "Creator:    %s\n", orDash(obj.Creator))
+	fmt.Fprintf(w, "Note:       %s\n", orDash(obj.Note))
+	if obj.Source == nil {
+		fmt.Fprintln(w, "Source:     unknown, the resource was duplicated by an older version of duplik8s")
+		return nil
+	}
+	fmt.Fprintf(w, "Source:     %s (resource version %s)\n", obj.Source, orDash(obj.Source.ResourceVersion))
+
+	if obj.Options != nil {
+		options, err := yaml.Marshal(obj.Options)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "Options:")
+		for _, line := range strings.Split(strings.TrimSpace(string(options)), "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+
+	if source == nil {
+		fmt.Fprintf(w, "\nThe source %s no longer exists, the changes can't be computed.\n", obj.Source)
+		return nil
+	}
+	if source.GetResourceVersion() != obj.Source.ResourceVersion {
+		fmt.Fprintf(
+			w,
+			"\nThe source has been updated since it was duplicated (resource version %s), "+
+				"the changes include its later updates.\n",
+			source.GetResourceVersion(),
+		)
+	}
+	changes := core.Compare(source, duplicate)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "Changes:    none")
+		return nil
+	}
+	fmt.Fprintln(w, "Changes:")
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %s\n", c)
+	}
+	return nil
+}
+
+func NewDescribeCmd(client core.Client) *cobra.Command {
+	describeCmd := &cobra.Command{
+		Use:   "describe [KIND/]NAME",
+		Short: "Show the source of a duplicated resource and what was changed compared with it.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			opts, err := NewKubeOptions(cmd, args)
+			if err != nil {
+				return err
+			}
+			if client == nil {
+				client, err = clients.NewDuplik8sClient(opts)
+				if err != nil {
+					return err
+				}
+			}
+			obj, err := findDuplicated(cmd.Context(), client, opts.Namespace, args[0])
+			if err != nil {
+				return err
+			}
+			return describe(cmd.Context(), cmd.OutOrStdout(), client, obj)
+		},
+	}
+	return describeCmd
+}
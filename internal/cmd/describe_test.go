@@ -0,0 +1,108 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/test"
+	"github.com/telemaco019/duplik8s/internal/test/mocks"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+	"time"
+)
+
+func newPod(name, uid, resourceVersion string, command ...any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":            name,
+			"namespace":       "default",
+			"uid":             uid,
+			"resourceVersion": resourceVersion,
+		},
+		"spec": map[string]any{
+			"containers": []any{map[string]any{"name": "nginx", "command": command}},
+		},
+	}}
+}
+
+func newDescribeClient(source *unstructured.Unstructured) *mocks.PodClient {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	duplicated := newDuplicatedObject("nginx-duplik8ted", "Pod", time.Hour, nil)
+	duplicated.ObjectKind.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Pod"})
+	duplicated.Source = &core.SourceObject{
+		APIVersion:      "v1",
+		Kind:            "Pod",
+		Name:            "nginx",
+		UID:             "source-uid",
+		ResourceVersion: "42",
+	}
+	duplicated.Options = &core.DuplicateOpts{Command: []string{"sh"}}
+	client.ListDuplicatedResult = []core.DuplicatedObject{duplicated}
+	client.Objects = []*unstructured.Unstructured{newPod("nginx-duplik8ted", "duplicate-uid", "50", "sh")}
+	if source != nil {
+		client.Objects = append(client.Objects, source)
+	}
+	return client
+}
+
+func Test_Describe(t *testing.T) {
+	client := newDescribeClient(newPod("nginx", "source-uid", "42", "nginx"))
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "describe", "pod/nginx-duplik8ted")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Source:     Pod/nginx (resource version 42)")
+	assert.Contains(t, output, "Options:\n  command:\n  - sh\n")
+	assert.Contains(t, output, `~ spec.containers[nginx].command: ["nginx"] -> ["sh"]`)
+	assert.NotContains(t, output, "has been updated")
+}
+
+func Test_Describe_SourceUpdated(t *testing.T) {
+	client := newDescribeClient(newPod("nginx", "source-uid", "43", "nginx"))
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "describe", "nginx-duplik8ted")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "The source has been updated since it was duplicated (resource version 43)")
+}
+
+func Test_Describe_SourceRecreated(t *testing.T) {
+	client := newDescribeClient(newPod("nginx", "other-uid", "60", "nginx"))
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "describe", "nginx-duplik8ted")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "The source Pod/nginx no longer exists")
+}
+
+func Test_Describe_NotFound(t *testing.T) {
+	client := newDescribeClient(nil)
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "describe", "deployment/nginx-duplik8ted")
+	assert.ErrorContains(t, err, `duplicated resource "deployment/nginx-duplik8ted" not found`)
+}
+
+func Test_Describe_Ambiguous(t *testing.T) {
+	client := newDescribeClient(nil)
+	deploy := newDuplicatedObject("nginx-duplik8ted", "Deployment", time.Hour, nil)
+	deploy.ObjectKind.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	client.ListDuplicatedResult = append(client.ListDuplicatedResult, deploy)
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "describe", "nginx-duplik8ted")
+	assert.ErrorContains(t, err, "use KIND/NAME: pod/nginx-duplik8ted, deployment.apps/nginx-duplik8ted")
+}
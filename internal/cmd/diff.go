@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	"io"
+)
+
+// diffDuplicated prints the unified diff between the current version of the source of the provided
+// duplicated resource and the current version of the duplicated resource.
+func diffDuplicated(ctx context.Context, w io.Writer, client core.Client, obj core.DuplicatedObject) error {
+	if obj.Source == nil {
+		return fmt.Errorf("the source of %s is unknown, it was duplicated by an older version of duplik8s", obj.Name)
+	}
+	duplicate, err := client.Get(ctx, obj.ObjectKind.GroupVersionKind(), obj.Namespace, obj.Name)
+	if err != nil {
+		return err
+	}
+	source, err := getSource(ctx, client, obj)
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("the source %s no longer exists", obj.Source)
+	}
+	diff, err := core.Diff(source, duplicate)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Fprintf(w, "No differences between %s and %s\n", obj.Source, obj.Name)
+		return nil
+	}
+	fmt.Fprint(w, utils.ColorizeDiff(diff))
+	return nil
+}
+
+func NewDiffCmd(client core.Client) *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff [KIND/]NAME",
+		Short: "Show the differences between a duplicated resource and its source.",
+		Long: "Show a unified diff between the labels, annotations and spec of the source of a duplicated resource " +
+			"and the ones of the duplicated resource, including the changes made after the duplication.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			opts, err := NewKubeOptions(cmd, args)
+			if err != nil {
+				return err
+			}
+			if client == nil {
+				client, err = clients.NewDuplik8sClient(opts)
+				if err != nil {
+					return err
+				}
+			}
+			obj, err := findDuplicated(cmd.Context(), client, opts.Namespace, args[0])
+			if err != nil {
+				return err
+			}
+			return diffDuplicated(cmd.Context(), cmd.OutOrStdout(), client, obj)
+		},
+	}
+	return diffCmd
+}
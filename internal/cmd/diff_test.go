@@ -0,0 +1,47 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/test"
+	"testing"
+)
+
+func Test_Diff(t *testing.T) {
+	client := newDescribeClient(newPod("nginx", "source-uid", "42", "nginx"))
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "diff", "pod/nginx-duplik8ted")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "--- Pod/nginx (source)\n+++ Pod/nginx-duplik8ted (duplicate)\n")
+	assert.Contains(t, output, "-    - nginx\n+    - sh\n")
+}
+
+func Test_Diff_NoDifferences(t *testing.T) {
+	client := newDescribeClient(newPod("nginx", "source-uid", "42", "sh"))
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "diff", "nginx-duplik8ted")
+	assert.NoError(t, err)
+	assert.Equal(t, "No differences between Pod/nginx and nginx-duplik8ted\n", output)
+}
+
+func Test_Diff_SourceDeleted(t *testing.T) {
+	client := newDescribeClient(nil)
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "diff", "nginx-duplik8ted")
+	assert.EqualError(t, err, "the source Pod/nginx no longer exists")
+}
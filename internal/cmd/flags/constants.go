@@ -21,8 +21,64 @@ const (
 	NAMESPACE   = "namespace"
 	KUBECONTEXT = "context"
 
+	NAME                     = "name"
+	NAME_TEMPLATE            = "name-template"
+	TTL                      = "ttl"
+	NOTE                     = "note"
+	DIFF                     = "diff"
 	COMMAND_OVERRIDE         = "command-override"
 	ARGS_OVERRIDE            = "args-override"
+	KEEP_COMMAND             = "keep-command"
+	LIVENESS_PROBE           = "liveness-probe"
+	READINESS_PROBE          = "readiness-probe"
+	STARTUP_PROBE            = "startup-probe"
+	POST_START_HOOK          = "post-start-hook"
+	PRE_STOP_HOOK            = "pre-stop-hook"
 	INTERACTIVE_SHELL        = "shell"
+	CONTAINER                = "container"
+	TARGET_CONTAINER         = "target-container"
+	CONTAINER_COMMAND        = "container-command"
+	CONTAINER_ARGS           = "container-args"
+	SHELL_PATH               = "shell-path"
+	SHELL_PREFERENCE         = "shell-preference"
+	WAIT_TIMEOUT             = "wait-timeout"
+	DEBUG_TOOLS              = "debug-tools"
+	DEBUG_TOOLS_IMAGE        = "debug-tools-image"
 	PRESERVE_INIT_CONTAINERS = "preserve-init-containers"
+	KEEP_INIT_CONTAINER      = "keep-init-container"
+	DROP_INIT_CONTAINER      = "drop-init-container"
+	KEEP_LABELS              = "keep-labels"
+	METADATA                 = "metadata"
+	METADATA_ALLOW           = "metadata-allow"
+	METADATA_DENY            = "metadata-deny"
+	METADATA_PRESET          = "metadata-preset"
+
+	RERUN = "rerun"
+	NODE  = "node"
+
+	TEMPLATE_PATH = "template-path"
+
+	ABANDONED  = "abandoned"
+	EXPIRED    = "expired"
+	YES        = "yes"
+	KIND       = "kind"
+	OLDER_THAN = "older-than"
+	SELECTOR   = "selector"
+	CASCADE    = "cascade"
+	WAIT       = "wait"
+
+	ALL_NAMESPACES  = "all-namespaces"
+	INTERVAL        = "interval"
+	MAX_AGE         = "max-age"
+	DRY_RUN         = "dry-run"
+	METRICS_ADDRESS = "metrics-address"
+
+	ABANDONED_GRACE_PERIOD = "abandoned-grace-period"
+
+	OUTPUT  = "output"
+	SORT_BY = "sort-by"
+
+	MINE       = "mine"
+	OWNER      = "owner"
+	ALL_OWNERS = "all-owners"
 )
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/telemaco019/duplik8s/internal/cmd/flags"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/duplicators"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// newGenericDuplicateCmd returns a command that duplicates any resource embedding a Pod template.
+// The first argument is the resource to duplicate, the optional second one the name of the object.
+func newGenericDuplicateCmd(duplicator core.Duplicator, client core.Client) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		resource := args[0]
+
+		// resolve the resource
+		gvr := schema.ParseGroupResource(resource).WithVersion("")
+		if duplicator == nil {
+			opts, err := NewKubeOptions(cmd, args)
+			if err != nil {
+				return err
+			}
+			templatePaths, err := cmd.Flags().GetStringSlice(flags.TEMPLATE_PATH)
+			if err != nil {
+				return err
+			}
+			unstructuredClient, err := duplicators.NewUnstructuredClient(opts, resource, templatePaths)
+			if err != nil {
+				return err
+			}
+			duplicator = unstructuredClient
+			gvr = unstructuredClient.Resource()
+		}
+		if command, ok := core.DedicatedCommands[gvr.GroupResource()]; ok {
+			return fmt.Errorf("%s can't be duplicated as generic resources, use 'kubectl duplicate %s' instead", resource, command)
+		}
+
+		factory := func(opts utils.KubeOptions) (core.Duplicator, error) {
+			return duplicator, nil
+		}
+		run := newDuplicateCmd(factory, client, gvr)
+		return run(cmd, args[1:])
+	}
+}
+
+func addGenericFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(
+		flags.TEMPLATE_PATH,
+		nil,
+		"Path of the Pod template in the duplicated resource, in dot notation (e.g. spec.template). "+
+			"Useful for custom resources not supported out of the box.",
+	)
+}
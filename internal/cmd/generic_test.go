@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/test"
+	"github.com/telemaco019/duplik8s/internal/test/mocks"
+	"testing"
+)
+
+func Test_GenericNoArgsShowsHelp(t *testing.T) {
+	podClient := mocks.NewPodClient(
+		mocks.ListPodsResult{},
+		nil,
+	)
+	cmd := NewRootCmd(podClient, podClient)
+	output, err := test.ExecuteCommand(cmd)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "kubectl duplicate")
+}
+
+func Test_GenericDuplicate(t *testing.T) {
+	podClient := mocks.NewPodClient(
+		mocks.ListPodsResult{},
+		nil,
+	)
+	cmd := NewRootCmd(podClient, podClient)
+	_, err := test.ExecuteCommand(cmd, "rollouts.argoproj.io", "rollout-1", "--template-path", "spec.template")
+	assert.NoError(t, err)
+}
+
+func Test_GenericNoObjectsAvailable(t *testing.T) {
+	podClient := mocks.NewPodClient(
+		mocks.ListPodsResult{},
+		nil,
+	)
+	cmd := NewRootCmd(podClient, podClient)
+	_, err := test.ExecuteCommand(cmd, "replicasets")
+	assert.EqualError(t, err, `no replicasets available in namespace "default"`)
+}
+
+func Test_GenericRejectsDedicatedResources(t *testing.T) {
+	testCases := []struct {
+		resource    string
+		expectedErr string
+	}{
+		{
+			resource:    "cronjobs.batch",
+			expectedErr: "cronjobs.batch can't be duplicated as generic resources, use 'kubectl duplicate cronjob' instead",
+		},
+		{
+			resource:    "daemonsets.apps",
+			expectedErr: "daemonsets.apps can't be duplicated as generic resources, use 'kubectl duplicate daemonset' instead",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.resource, func(t *testing.T) {
+			podClient := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+			cmd := NewRootCmd(podClient, podClient)
+			_, err := test.ExecuteCommand(cmd, tc.resource, "my-object")
+			assert.EqualError(t, err, tc.expectedErr)
+			assert.Zero(t, podClient.DuplicateOpts)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/cmd/flags"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/janitor"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func runJanitor(ctx context.Context, client core.Client, opts janitor.Options, metricsAddress string) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	j, err := janitor.New(client, opts, registry, logger)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{Addr: metricsAddress, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("serving metrics", "address", metricsAddress)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	logger.Info(
+		"starting janitor",
+		"namespace", opts.Namespace,
+		"interval", opts.Interval.String(),
+		"maxAge", opts.MaxAge.String(),
+		"abandoned", opts.Abandoned,
+		"abandonedGracePeriod", opts.AbandonedGracePeriod.String(),
+		"dryRun", opts.DryRun,
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case err := <-serverErr:
+			logger.Error("metrics server failed", "error", err)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err = j.Run(ctx)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	return errors.Join(err, server.Shutdown(shutdownCtx))
+}
+
+func NewJanitorCmd(client core.Client) *cobra.Command {
+	janitorCmd := &cobra.Command{
+		Use:   "janitor",
+		Short: "Periodically delete expired duplicated resources.",
+		Long: "Periodically delete the duplicated resources whose TTL has expired or that are older than --max-age, " +
+			"exposing Prometheus metrics. Meant to run in the cluster as a Deployment.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			opts, err := NewKubeOptions(cmd, args)
+			if err != nil {
+				return err
+			}
+			if client == nil {
+				client, err = clients.NewDuplik8sClient(opts)
+				if err != nil {
+					return err
+				}
+			}
+
+			janitorOpts := janitor.Options{Namespace: opts.Namespace}
+			allNamespaces, err := cmd.Flags().GetBool(flags.ALL_NAMESPACES)
+			if err != nil {
+				return err
+			}
+			if allNamespaces {
+				janitorOpts.Namespace = ""
+			}
+			janitorOpts.Interval, err = cmd.Flags().GetDuration(flags.INTERVAL)
+			if err != nil {
+				return err
+			}
+			if janitorOpts.Interval <= 0 {
+				return fmt.Errorf("--%s must be positive", flags.INTERVAL)
+			}
+			janitorOpts.MaxAge, err = cmd.Flags().GetDuration(flags.MAX_AGE)
+			if err != nil {
+				return err
+			}
+			janitorOpts.Abandoned, err = cmd.Flags().GetBool(flags.ABANDONED)
+			if err != nil {
+				return err
+			}
+			janitorOpts.AbandonedGracePeriod, err = cmd.Flags().GetDuration(flags.ABANDONED_GRACE_PERIOD)
+			if err != nil {
+				return err
+			}
+			if janitorOpts.AbandonedGracePeriod < 0 {
+				return fmt.Errorf("--%s can't be negative", flags.ABANDONED_GRACE_PERIOD)
+			}
+			janitorOpts.DryRun, err = cmd.Flags().GetBool(flags.DRY_RUN)
+			if err != nil {
+				return err
+			}
+			metricsAddress, err := cmd.Flags().GetString(flags.METRICS_ADDRESS)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runJanitor(ctx, client, janitorOpts, metricsAddress)
+		},
+	}
+	janitorCmd.Flags().BoolP(flags.ALL_NAMESPACES, "A", false, "Collect the duplicated resources of all namespaces.")
+	janitorCmd.Flags().Duration(flags.INTERVAL, 5*time.Minute, "Time between two collections.")
+	janitorCmd.Flags().Duration(
+		flags.MAX_AGE,
+		0,
+		"Delete the duplicated resources older than the provided age, even without TTL. If omitted, only expired "+
+			"resources are deleted.",
+	)
+	janitorCmd.Flags().Bool(
+		flags.ABANDONED,
+		false,
+		"Delete the duplicated resources whose shell session has been abandoned, e.g. because the terminal died.",
+	)
+	janitorCmd.Flags().Duration(
+		flags.ABANDONED_GRACE_PERIOD,
+		10*time.Minute,
+		"Time a shell session must have been abandoned for before --abandoned deletes its duplicated resources.",
+	)
+	janitorCmd.Flags().Bool(flags.DRY_RUN, false, "Only log the resources that would be deleted.")
+	janitorCmd.Flags().String(flags.METRICS_ADDRESS, ":8080", "Address of the Prometheus metrics endpoint.")
+	return janitorCmd
+}
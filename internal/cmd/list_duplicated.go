@@ -21,21 +21,110 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/cmd/flags"
 	"github.com/telemaco019/duplik8s/internal/core"
+	"io"
+	"maps"
+	"slices"
+	"strings"
 )
 
-func listDuplicatedResources(client core.Client, namespace string) error {
+// sortKeys are the values of --sort-by, with the functions comparing the duplicated resources by them.
+var sortKeys = map[string]func(a, b core.DuplicatedObject) int{
+	"namespace": func(a, b core.DuplicatedObject) int { return strings.Compare(a.Namespace, b.Namespace) },
+	"kind": func(a, b core.DuplicatedObject) int {
+		return strings.Compare(a.ObjectKind.GroupVersionKind().Kind, b.ObjectKind.GroupVersionKind().Kind)
+	},
+	"name": func(a, b core.DuplicatedObject) int { return strings.Compare(a.Name, b.Name) },
+	// youngest first
+	"age": func(a, b core.DuplicatedObject) int {
+		return b.CreationTimestamp.Time.Compare(a.CreationTimestamp.Time)
+	},
+	// soonest to expire first, resources without TTL last
+	"ttl": func(a, b core.DuplicatedObject) int {
+		switch {
+		case a.ExpiresAt == nil && b.ExpiresAt == nil:
+			return 0
+		case a.ExpiresAt == nil:
+			return 1
+		case b.ExpiresAt == nil:
+			return -1
+		}
+		return a.ExpiresAt.Time.Compare(b.ExpiresAt.Time)
+	},
+	"status":  func(a, b core.DuplicatedObject) int { return strings.Compare(a.Status, b.Status) },
+	"node":    func(a, b core.DuplicatedObject) int { return strings.Compare(a.Node, b.Node) },
+	"creator": func(a, b core.DuplicatedObject) int { return strings.Compare(a.Creator, b.Creator) },
+	"source": func(a, b core.DuplicatedObject) int {
+		var sourceA, sourceB string
+		if a.Source != nil {
+			sourceA = a.Source.String()
+		}
+		if b.Source != nil {
+			sourceB = b.Source.String()
+		}
+		return strings.Compare(sourceA, sourceB)
+	},
+}
+
+type listOptions struct {
+	// Output is the output format. If empty, the default table is printed.
+	Output string
+	// SortBy is the key the resources are sorted by. If empty, they are printed in the order they are listed.
+	SortBy string
+	// Owner is the user whose resources are listed.
+	// If empty, the resources of every user are listed.
+	Owner string
+}
+
+func listDuplicatedResources(w io.Writer, client core.Client, namespace string, opts listOptions) error {
 	duplicatedObjs, err := client.ListDuplicated(context.Background(), namespace)
 	if err != nil {
 		return err
 	}
+	if opts.Owner != "" {
+		duplicatedObjs = slices.DeleteFunc(duplicatedObjs, func(obj core.DuplicatedObject) bool {
+			return !ownedBy(obj, opts.Owner)
+		})
+	}
 
-	if len(duplicatedObjs) == 0 {
-		fmt.Printf("No duplicated resources found in namespace %q\n", namespace)
+	if len(duplicatedObjs) == 0 && (opts.Output == "" || opts.Output == "wide") {
+		if namespace == "" {
+			fmt.Fprintln(w, "No duplicated resources found")
+			return nil
+		}
+		fmt.Fprintf(w, "No duplicated resources found in namespace %q\n", namespace)
 		return nil
 	}
-	renderDuplicatedObjects(duplicatedObjs)
-	return nil
+	if opts.SortBy != "" {
+		slices.SortStableFunc(duplicatedObjs, sortKeys[opts.SortBy])
+	}
+	return printDuplicatedObjects(w, duplicatedObjs, opts.Output)
+}
+
+func newListOptions(cmd *cobra.Command) (listOptions, error) {
+	var opts listOptions
+	var err error
+	opts.Output, err = cmd.Flags().GetString(flags.OUTPUT)
+	if err != nil {
+		return opts, err
+	}
+	if err = validateOutput(opts.Output); err != nil {
+		return opts, err
+	}
+	opts.SortBy, err = cmd.Flags().GetString(flags.SORT_BY)
+	if err != nil {
+		return opts, err
+	}
+	if _, ok := sortKeys[opts.SortBy]; opts.SortBy != "" && !ok {
+		return opts, fmt.Errorf(
+			"invalid --%s %q: must be one of %s",
+			flags.SORT_BY,
+			opts.SortBy,
+			strings.Join(slices.Sorted(maps.Keys(sortKeys)), ", "),
+		)
+	}
+	return opts, nil
 }
 
 func NewListDuplicatedCmd(client core.Client) *cobra.Command {
@@ -49,15 +138,45 @@ func NewListDuplicatedCmd(client core.Client) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			listOpts, err := newListOptions(cmd)
+			if err != nil {
+				return err
+			}
+			allNamespaces, err := cmd.Flags().GetBool(flags.ALL_NAMESPACES)
+			if err != nil {
+				return err
+			}
+			if allNamespaces {
+				opts.Namespace = ""
+			}
 			if client == nil {
 				client, err = clients.NewDuplik8sClient(opts)
 				if err != nil {
 					return err
 				}
 			}
-			return listDuplicatedResources(client, opts.Namespace)
+			listOpts.Owner, err = newOwnerFilter(cmd, client)
+			if err != nil {
+				return err
+			}
+			return listDuplicatedResources(cmd.OutOrStdout(), client, opts.Namespace, listOpts)
 		},
 	}
-	addOverrideFlags(podCmd)
+	podCmd.Flags().BoolP(flags.ALL_NAMESPACES, "A", false, "List the duplicated resources of all namespaces.")
+	podCmd.Flags().StringP(
+		flags.OUTPUT,
+		"o",
+		"",
+		"Output format: wide, name, json, yaml or custom-columns=HEADER:JSONPATH,... "+
+			"(e.g. custom-columns=NAME:.metadata.name,NODE:.spec.nodeName).",
+	)
+	podCmd.Flags().String(
+		flags.SORT_BY,
+		"",
+		"Sort the duplicated resources by namespace, kind, name, age, ttl, status, node, source or creator.",
+	)
+	podCmd.Flags().Bool(flags.MINE, false, "Only list the duplicated resources created by the current user.")
+	podCmd.Flags().String(flags.OWNER, "", "Only list the duplicated resources created by the provided user.")
+	podCmd.MarkFlagsMutuallyExclusive(flags.MINE, flags.OWNER)
 	return podCmd
 }
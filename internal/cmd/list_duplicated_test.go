@@ -0,0 +1,138 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/test"
+	"github.com/telemaco019/duplik8s/internal/test/mocks"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newListClient() *mocks.PodClient {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	deploy := newDuplicatedObject("api-duplik8ted", "Deployment", 2*time.Hour, nil)
+	deploy.ObjectKind.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	deploy.Source = &core.SourceObject{Kind: "Deployment", Name: "api"}
+	deploy.Creator = "bob"
+	deploy.Object = map[string]any{"metadata": map[string]any{"name": "api-duplik8ted"}}
+	pod := newDuplicatedObject("nginx-duplik8ted", "Pod", time.Hour, nil)
+	pod.ObjectKind.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Pod"})
+	pod.Node = "node-1"
+	pod.Object = map[string]any{
+		"metadata": map[string]any{"name": "nginx-duplik8ted"},
+		"spec":     map[string]any{"nodeName": "node-1"},
+	}
+	pod.Creator = "alice"
+	client.ListDuplicatedResult = []core.DuplicatedObject{pod, deploy}
+	return client
+}
+
+func Test_List_AllNamespaces(t *testing.T) {
+	client := newListClient()
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "list", "-n", "default", "-A")
+	assert.NoError(t, err)
+	assert.Equal(t, "", client.ListedNamespace)
+}
+
+func Test_List_Name(t *testing.T) {
+	client := newListClient()
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "list", "-o", "name", "--sort-by", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "deployment.apps/api-duplik8ted\npod/nginx-duplik8ted\n", output)
+}
+
+func Test_List_JSON(t *testing.T) {
+	client := newListClient()
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "list", "-o", "json", "--sort-by", "age")
+	assert.NoError(t, err)
+
+	var list duplicatedObjectListOutput
+	assert.NoError(t, json.Unmarshal([]byte(output), &list))
+	assert.Len(t, list.Items, 2)
+	assert.Equal(t, "nginx-duplik8ted", list.Items[0].Name)
+	assert.Equal(t, "v1", list.Items[0].APIVersion)
+	assert.Equal(t, "alice", list.Items[0].Creator)
+	assert.Equal(t, &core.SourceObject{Kind: "Deployment", Name: "api"}, list.Items[1].Source)
+}
+
+func Test_List_CustomColumns(t *testing.T) {
+	client := newListClient()
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "list", "-o", "custom-columns=NAME:.metadata.name,NODE:.spec.nodeName")
+	assert.NoError(t, err)
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"NAME", "NODE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"nginx-duplik8ted", "node-1"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"api-duplik8ted", "-"}, strings.Fields(lines[2]))
+}
+
+func Test_List_Owner(t *testing.T) {
+	client := newListClient()
+	cmd := NewRootCmd(client, client)
+	output, err := test.ExecuteCommand(cmd, "list", "-o", "name", "--mine")
+	assert.NoError(t, err)
+	assert.Equal(t, "pod/nginx-duplik8ted\n", output)
+
+	client = newListClient()
+	cmd = NewRootCmd(client, client)
+	output, err = test.ExecuteCommand(cmd, "list", "-o", "name", "--owner", "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, "deployment.apps/api-duplik8ted\n", output)
+
+	// the resources whose creator is unknown are only listed along with the ones of every user
+	client = newListClient()
+	client.ListDuplicatedResult[1].Creator = ""
+	cmd = NewRootCmd(client, client)
+	output, err = test.ExecuteCommand(cmd, "list", "-o", "name", "--mine")
+	assert.NoError(t, err)
+	assert.Equal(t, "pod/nginx-duplik8ted\n", output)
+
+	client = newListClient()
+	client.ListDuplicatedResult[1].Creator = ""
+	cmd = NewRootCmd(client, client)
+	output, err = test.ExecuteCommand(cmd, "list", "-o", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "pod/nginx-duplik8ted\ndeployment.apps/api-duplik8ted\n", output)
+}
+
+func Test_List_InvalidOptions(t *testing.T) {
+	client := newListClient()
+	cmd := NewRootCmd(client, client)
+	_, err := test.ExecuteCommand(cmd, "list", "-o", "xml")
+	assert.ErrorContains(t, err, "unsupported output format")
+
+	_, err = test.ExecuteCommand(cmd, "list", "-o", "custom-columns=NAME")
+	assert.ErrorContains(t, err, "invalid custom column")
+
+	_, err = test.ExecuteCommand(cmd, "list", "-o", "wide", "--sort-by", "size")
+	assert.ErrorContains(t, err, "invalid --sort-by")
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/jsonpath"
+	"os"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"strings"
+)
+
+const customColumnsPrefix = "custom-columns="
+
+// duplicatedObjectOutput is the representation of a duplicated resource in the structured outputs.
+type duplicatedObjectOutput struct {
+	APIVersion        string              `json:"apiVersion"`
+	Kind              string              `json:"kind"`
+	Namespace         string              `json:"namespace"`
+	Name              string              `json:"name"`
+	CreationTimestamp metav1.Time         `json:"creationTimestamp"`
+	ExpiresAt         *metav1.Time        `json:"expiresAt,omitempty"`
+	Status            string              `json:"status,omitempty"`
+	Node              string              `json:"node,omitempty"`
+	Source            *core.SourceObject  `json:"source,omitempty"`
+	Options           *core.DuplicateOpts `json:"options,omitempty"`
+	Creator           string              `json:"creator,omitempty"`
+	Note              string              `json:"note,omitempty"`
+	Labels            map[string]string   `json:"labels,omitempty"`
+}
+
+type duplicatedObjectListOutput struct {
+	Items []duplicatedObjectOutput `json:"items"`
+}
+
+func newDuplicatedObjectOutput(obj core.DuplicatedObject) duplicatedObjectOutput {
+	apiVersion, kind := obj.ObjectKind.GroupVersionKind().ToAPIVersionAndKind()
+	return duplicatedObjectOutput{
+		APIVersion:        apiVersion,
+		Kind:              kind,
+		Namespace:         obj.Namespace,
+		Name:              obj.Name,
+		CreationTimestamp: obj.CreationTimestamp,
+		ExpiresAt:         obj.ExpiresAt,
+		Status:            obj.Status,
+		Node:              obj.Node,
+		Source:            obj.Source,
+		Options:           obj.Options,
+		Creator:           obj.Creator,
+		Note:              obj.Note,
+		Labels:            obj.Labels,
+	}
+}
+
+// outputColumn is a column of the table output.
+type outputColumn struct {
+	header string
+	value  func(obj core.DuplicatedObject) (string, error)
+}
+
+var defaultColumns = []outputColumn{
+	{header: "Namespace", value: func(obj core.DuplicatedObject) (string, error) { return obj.Namespace, nil }},
+	{header: "Kind", value: func(obj core.DuplicatedObject) (string, error) {
+		return obj.ObjectKind.GroupVersionKind().Kind, nil
+	}},
+	{header: "Name", value: func(obj core.DuplicatedObject) (string, error) { return obj.Name, nil }},
+	{header: "Source", value: func(obj core.DuplicatedObject) (string, error) {
+		if obj.Source == nil {
+			return "-", nil
+		}
+		return obj.Source.String(), nil
+	}},
+	{header: "Age", value: func(obj core.DuplicatedObject) (string, error) {
+		return utils.FormatAge(obj.CreationTimestamp), nil
+	}},
+	{header: "TTL", value: func(obj core.DuplicatedObject) (string, error) { return utils.FormatTTL(obj.ExpiresAt), nil }},
+	{header: "Creator", value: func(obj core.DuplicatedObject) (string, error) { return orDash(obj.Creator), nil }},
+}
+
+var wideColumns = append(slices.Clone(defaultColumns), []outputColumn{
+	{header: "Status", value: func(obj core.DuplicatedObject) (string, error) { return orDash(obj.Status), nil }},
+	{header: "Node", value: func(obj core.DuplicatedObject) (string, error) { return orDash(obj.Node), nil }},
+	{header: "Note", value: func(obj core.DuplicatedObject) (string, error) { return orDash(obj.Note), nil }},
+}...)
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// validateOutput returns an error if the provided output format is not supported.
+func validateOutput(output string) error {
+	switch output {
+	case "", "wide", "name", "json", "yaml":
+		return nil
+	}
+	if strings.HasPrefix(output, customColumnsPrefix) {
+		_, err := parseCustomColumns(strings.TrimPrefix(output, customColumnsPrefix))
+		return err
+	}
+	return fmt.Errorf("unsupported output format %q: must be one of wide, name, json, yaml or custom-columns=", output)
+}
+
+// printDuplicatedObjects prints the provided duplicated resources in the provided output format.
+// An empty format prints the default table.
+func printDuplicatedObjects(w io.Writer, objs []core.DuplicatedObject, output string) error {
+	switch output {
+	case "":
+		return renderTable(w, objs, defaultColumns)
+	case "wide":
+		return renderTable(w, objs, wideColumns)
+	case "name":
+		for _, obj := range objs {
+			gk := obj.ObjectKind.GroupVersionKind().GroupKind()
+			_, err := fmt.Fprintf(w, "%s/%s\n", strings.ToLower(gk.String()), obj.Name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json", "yaml":
+		list := duplicatedObjectListOutput{Items: make([]duplicatedObjectOutput, 0, len(objs))}
+		for _, obj := range objs {
+			list.Items = append(list.Items, newDuplicatedObjectOutput(obj))
+		}
+		data, err := json.MarshalIndent(list, "", "    ")
+		if err != nil {
+			return err
+		}
+		if output == "yaml" {
+			data, err = yaml.JSONToYAML(data)
+			if err != nil {
+				return err
+			}
+		} else {
+			data = append(data, '\n')
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	if strings.HasPrefix(output, customColumnsPrefix) {
+		columns, err := parseCustomColumns(strings.TrimPrefix(output, customColumnsPrefix))
+		if err != nil {
+			return err
+		}
+		return renderTable(w, objs, columns)
+	}
+	return validateOutput(output)
+}
+
+// parseCustomColumns parses a list of HEADER:JSONPATH columns, whose JSONPath is evaluated
+// against the duplicated resources like kubectl does (e.g. NAME:.metadata.name,NODE:.spec.nodeName).
+func parseCustomColumns(spec string) ([]outputColumn, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("custom-columns format requires at least one column")
+	}
+	var columns []outputColumn
+	for _, column := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(column, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("invalid custom column %q: must be HEADER:JSONPATH", column)
+		}
+		if !strings.HasPrefix(path, "{") {
+			path = "{" + path + "}"
+		}
+		parser := jsonpath.New(header).AllowMissingKeys(true)
+		if err := parser.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid custom column %q: %w", column, err)
+		}
+		columns = append(columns, outputColumn{header: header, value: func(obj core.DuplicatedObject) (string, error) {
+			object := obj.Object
+			if object == nil {
+				object = map[string]any{}
+			}
+			var buf bytes.Buffer
+			if err := parser.Execute(&buf, object); err != nil {
+				return "", err
+			}
+			return orDash(buf.String()), nil
+		}})
+	}
+	return columns, nil
+}
+
+// renderTable prints the provided duplicated resources as a table with the provided columns.
+func renderTable(w io.Writer, objs []core.DuplicatedObject, columns []outputColumn) error {
+	headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	defaultStyle := lipgloss.NewStyle().Padding(0, 1)
+	headers := make([]string, 0, len(columns))
+	for _, c := range columns {
+		headers = append(headers, c.header)
+	}
+	t := table.New().Border(lipgloss.HiddenBorder()).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch row {
+			case 0:
+				return headerStyle
+			default:
+				return defaultStyle
+			}
+		}).
+		Headers(headers...)
+	for _, obj := range objs {
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			value, err := c.value(obj)
+			if err != nil {
+				return err
+			}
+			row = append(row, value)
+		}
+		t.Row(row...)
+	}
+	_, err := fmt.Fprint(w, t.Render()+"\n")
+	return err
+}
+
+func renderDuplicatedObjects(duplicatedObjs []core.DuplicatedObject) {
+	_ = renderTable(os.Stdout, duplicatedObjs, defaultColumns)
+}
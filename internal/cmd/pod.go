@@ -46,5 +46,6 @@ func NewPodCmd(duplicator core.Duplicator, client core.Client) *cobra.Command {
 		},
 	}
 	addOverrideFlags(podCmd)
+	addDryRunFlags(podCmd)
 	return podCmd
 }
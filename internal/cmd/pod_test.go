@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
 	"github.com/telemaco019/duplik8s/internal/test"
 	"github.com/telemaco019/duplik8s/internal/test/mocks"
 	"testing"
@@ -54,3 +55,122 @@ func Test_DuplicateError(t *testing.T) {
 	_, err := test.ExecuteCommand(cmd, "pod", "pod-1")
 	assert.EqualError(t, err, "error")
 }
+
+func Test_CreatorAndNote(t *testing.T) {
+	podClient := mocks.NewPodClient(
+		mocks.ListPodsResult{},
+		nil,
+	)
+	cmd := NewRootCmd(podClient, podClient)
+	_, err := test.ExecuteCommand(cmd, "pod", "pod-1", "--note", "INC-1234")
+	assert.NoError(t, err)
+	assert.Equal(t, mocks.CurrentUser, podClient.DuplicateOpts.Creator)
+	assert.Equal(t, "INC-1234", podClient.DuplicateOpts.Note)
+
+	// the duplication doesn't fail if the current user is unknown
+	podClient.User = ""
+	_, err = test.ExecuteCommand(NewRootCmd(podClient, podClient), "pod", "pod-1")
+	assert.NoError(t, err)
+	assert.Empty(t, podClient.DuplicateOpts.Creator)
+}
+
+func Test_DryRun(t *testing.T) {
+	testCases := []struct {
+		name           string
+		args           []string
+		expectedDryRun core.DryRunStrategy
+		expectedOutput string
+		expectedErr    string
+	}{
+		{name: "no dry run", args: nil, expectedDryRun: core.DryRunNone},
+		{name: "dry run", args: []string{"--dry-run"}, expectedDryRun: core.DryRunClient},
+		{name: "server", args: []string{"--dry-run=server"}, expectedDryRun: core.DryRunServer},
+		{name: "output", args: []string{"-o", "yaml"}, expectedDryRun: core.DryRunClient, expectedOutput: "yaml"},
+		{
+			name:           "output with server dry run",
+			args:           []string{"--dry-run=server", "-o", "json"},
+			expectedDryRun: core.DryRunServer,
+			expectedOutput: "json",
+		},
+		{name: "invalid dry run", args: []string{"--dry-run=true"}, expectedErr: "invalid --dry-run"},
+		{name: "invalid output", args: []string{"-o", "wide"}, expectedErr: "unsupported output format"},
+		{
+			name:        "shell",
+			args:        []string{"--dry-run", "--shell"},
+			expectedErr: "--shell can't be used with --dry-run or --output",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			podClient := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+			cmd := NewRootCmd(podClient, podClient)
+			_, err := test.ExecuteCommand(cmd, append([]string{"pod", "pod-1"}, tc.args...)...)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedDryRun, podClient.DuplicateOpts.DryRun)
+			assert.Equal(t, tc.expectedOutput, podClient.DuplicateOpts.Output)
+			// client dry runs don't contact the API server for determining the creator
+			if tc.expectedDryRun == core.DryRunClient {
+				assert.Empty(t, podClient.DuplicateOpts.Creator)
+			} else {
+				assert.Equal(t, mocks.CurrentUser, podClient.DuplicateOpts.Creator)
+			}
+		})
+	}
+}
+
+func Test_DiffFlag(t *testing.T) {
+	podClient := mocks.NewPodClient(
+		mocks.ListPodsResult{},
+		nil,
+	)
+	cmd := NewRootCmd(podClient, podClient)
+	_, err := test.ExecuteCommand(cmd, "pod", "pod-1", "--diff")
+	assert.NoError(t, err)
+	assert.True(t, podClient.DuplicateOpts.Diff)
+}
+
+func Test_ContainerFlags(t *testing.T) {
+	podClient := mocks.NewPodClient(
+		mocks.ListPodsResult{},
+		nil,
+	)
+	cmd := NewRootCmd(podClient, podClient)
+	_, err := test.ExecuteCommand(cmd, "pod", "pod-1", "-c", "debug", "--target-container", "app,worker")
+	assert.NoError(t, err)
+	// the shell container is not targeted by the overrides
+	assert.Equal(t, "debug", podClient.DuplicateOpts.ShellContainer)
+	assert.Equal(t, []string{"app", "worker"}, podClient.DuplicateOpts.Containers)
+}
+
+func Test_CommandOverride(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "default", args: nil, expected: []string{"/bin/sh"}},
+		{name: "debug tools", args: []string{"--debug-tools"}, expected: []string{"/duplik8s-tools/sh"}},
+		{
+			name:     "debug tools with command",
+			args:     []string{"--debug-tools", "--command-override", "/bin/bash"},
+			expected: []string{"/bin/bash"},
+		},
+		{name: "keep command", args: []string{"--debug-tools", "--keep-command"}, expected: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			podClient := mocks.NewPodClient(
+				mocks.ListPodsResult{},
+				nil,
+			)
+			cmd := NewRootCmd(podClient, podClient)
+			_, err := test.ExecuteCommand(cmd, append([]string{"pod", "pod-1"}, tc.args...)...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, podClient.DuplicateOpts.Command)
+		})
+	}
+}
@@ -28,11 +28,16 @@ func NewRootCmd(
 	client core.Client,
 ) *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use: "kubectl-duplicate",
+		Use: "kubectl-duplicate [resource] [name]",
 		Annotations: map[string]string{
 			cobra.CommandDisplayNameAnnotation: "kubectl duplicate",
 		},
 		Short: "duplik8s is a kubectl plugin for duplicating Kubernetes resources.",
+		Long: "duplik8s is a kubectl plugin for duplicating Kubernetes resources.\n\n" +
+			"Besides the resources listed below, any resource embedding a Pod template (e.g. ReplicaSets, " +
+			"Argo Rollouts, OpenShift DeploymentConfigs) can be duplicated with `kubectl duplicate <resource> [name]`.",
+		Args: cobra.MaximumNArgs(2),
+		RunE: newGenericDuplicateCmd(duplicator, client),
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: true,
 		},
@@ -46,12 +51,22 @@ func NewRootCmd(
 	configFlags.Namespace = &defaultNamespace
 	configFlags.AddFlags(rootCmd.PersistentFlags())
 
+	// flags for duplicating generic resources
+	addOverrideFlags(rootCmd)
+	addGenericFlags(rootCmd)
+
 	// add subcommands
 	rootCmd.AddCommand(NewPodCmd(duplicator, client))
 	rootCmd.AddCommand(NewDeployCmd(duplicator, client))
 	rootCmd.AddCommand(NewStatefulSetCmd(duplicator, client))
+	rootCmd.AddCommand(NewJobCmd(duplicator, client))
+	rootCmd.AddCommand(NewCronJobCmd(duplicator, client))
+	rootCmd.AddCommand(NewDaemonSetCmd(duplicator, client))
 	rootCmd.AddCommand(NewListDuplicatedCmd(client))
+	rootCmd.AddCommand(NewDescribeCmd(client))
+	rootCmd.AddCommand(NewDiffCmd(client))
 	rootCmd.AddCommand(NewCleanupCmd(client))
+	rootCmd.AddCommand(NewJanitorCmd(client))
 
 	return rootCmd
 }
@@ -18,9 +18,8 @@ package cmd
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
-	"github.com/charmbracelet/lipgloss"
-	"github.com/charmbracelet/lipgloss/table"
 	"github.com/spf13/cobra"
 	"github.com/telemaco019/duplik8s/internal/clients"
 	"github.com/telemaco019/duplik8s/internal/cmd/flags"
@@ -29,6 +28,9 @@ import (
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"path"
+	"strings"
+	"time"
 )
 
 type duplicatorFactory func(opts utils.KubeOptions) (core.Duplicator, error)
@@ -49,6 +51,17 @@ func newDuplicateCmd(newDuplicator duplicatorFactory, client core.Client, gvr sc
 				return err
 			}
 		}
+		name, err := cmd.Flags().GetString(flags.NAME)
+		if err != nil {
+			return err
+		}
+		nameTemplate, err := cmd.Flags().GetString(flags.NAME_TEMPLATE)
+		if err != nil {
+			return err
+		}
+		if _, err = core.ParseNameTemplate(nameTemplate); err != nil {
+			return err
+		}
 		cmdOverride, err := cmd.Flags().GetStringSlice(flags.COMMAND_OVERRIDE)
 		if err != nil {
 			return err
@@ -57,22 +70,156 @@ func newDuplicateCmd(newDuplicator duplicatorFactory, client core.Client, gvr sc
 		if err != nil {
 			return err
 		}
+		keepCommand, err := cmd.Flags().GetBool(flags.KEEP_COMMAND)
+		if err != nil {
+			return err
+		}
+		if keepCommand {
+			cmdOverride, argsOverride = nil, nil
+		}
+		policies := make(map[string]core.OverridePolicies)
+		for _, f := range []string{
+			flags.LIVENESS_PROBE,
+			flags.READINESS_PROBE,
+			flags.STARTUP_PROBE,
+			flags.POST_START_HOOK,
+			flags.PRE_STOP_HOOK,
+		} {
+			values, err := cmd.Flags().GetStringArray(f)
+			if err != nil {
+				return err
+			}
+			policies[f], err = core.ParseOverridePolicies(values)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", f, err)
+			}
+		}
 		interactiveShell, err := cmd.Flags().GetBool(flags.INTERACTIVE_SHELL)
 		if err != nil {
 			return err
 		}
+		shellContainer, err := cmd.Flags().GetString(flags.CONTAINER)
+		if err != nil {
+			return err
+		}
+		containers, err := cmd.Flags().GetStringSlice(flags.TARGET_CONTAINER)
+		if err != nil {
+			return err
+		}
+		containerCommands, err := cmd.Flags().GetStringArray(flags.CONTAINER_COMMAND)
+		if err != nil {
+			return err
+		}
+		containerArgs, err := cmd.Flags().GetStringArray(flags.CONTAINER_ARGS)
+		if err != nil {
+			return err
+		}
+		containerOverrides, err := parseContainerOverrides(containerCommands, containerArgs)
+		if err != nil {
+			return err
+		}
+		shellPath, err := cmd.Flags().GetString(flags.SHELL_PATH)
+		if err != nil {
+			return err
+		}
+		shellPreference, err := cmd.Flags().GetStringSlice(flags.SHELL_PREFERENCE)
+		if err != nil {
+			return err
+		}
+		waitTimeout, err := cmd.Flags().GetDuration(flags.WAIT_TIMEOUT)
+		if err != nil {
+			return err
+		}
+		debugTools, err := cmd.Flags().GetBool(flags.DEBUG_TOOLS)
+		if err != nil {
+			return err
+		}
+		debugToolsImage, err := cmd.Flags().GetString(flags.DEBUG_TOOLS_IMAGE)
+		if err != nil {
+			return err
+		}
+		// the image may not have any shell, so the idle containers run the one of the debug tools
+		if debugTools && !keepCommand && !cmd.Flags().Changed(flags.COMMAND_OVERRIDE) {
+			cmdOverride = []string{path.Join(core.DEBUG_TOOLS_DIR, "sh")}
+		}
 		preserveInitContainers, err := cmd.Flags().GetBool(flags.PRESERVE_INIT_CONTAINERS)
 		if err != nil {
 			return err
 		}
+		keepInitContainers, err := cmd.Flags().GetStringSlice(flags.KEEP_INIT_CONTAINER)
+		if err != nil {
+			return err
+		}
+		dropInitContainers, err := cmd.Flags().GetStringSlice(flags.DROP_INIT_CONTAINER)
+		if err != nil {
+			return err
+		}
+		keepLabels, err := cmd.Flags().GetBool(flags.KEEP_LABELS)
+		if err != nil {
+			return err
+		}
+		ttl, err := cmd.Flags().GetDuration(flags.TTL)
+		if err != nil {
+			return err
+		}
+		metadata, err := newMetadataPolicy(cmd)
+		if err != nil {
+			return err
+		}
+		note, err := cmd.Flags().GetString(flags.NOTE)
+		if err != nil {
+			return err
+		}
+		diff, err := cmd.Flags().GetBool(flags.DIFF)
+		if err != nil {
+			return err
+		}
+		dryRun, output, err := newDryRunOptions(cmd)
+		if err != nil {
+			return err
+		}
+		if interactiveShell && dryRun != core.DryRunNone {
+			return fmt.Errorf("--%s can't be used with --%s or --%s", flags.INTERACTIVE_SHELL, flags.DRY_RUN, flags.OUTPUT)
+		}
+		// The creator is informative only, so the duplication doesn't fail if it can't be determined.
+		// Client dry runs don't contact the API server, so it isn't determined for them.
+		var creator string
+		if dryRun != core.DryRunClient {
+			creator, _ = client.CurrentUser(context.Background())
+		}
 
 		// Avoid printing usage information on errors
 		cmd.SilenceUsage = true
 		options := core.DuplicateOpts{
+			Name:                   name,
+			NameTemplate:           nameTemplate,
 			Command:                cmdOverride,
 			Args:                   argsOverride,
+			LivenessProbe:          policies[flags.LIVENESS_PROBE],
+			ReadinessProbe:         policies[flags.READINESS_PROBE],
+			StartupProbe:           policies[flags.STARTUP_PROBE],
+			PostStartHook:          policies[flags.POST_START_HOOK],
+			PreStopHook:            policies[flags.PRE_STOP_HOOK],
 			StartInteractiveShell:  interactiveShell,
+			Containers:             containers,
+			ContainerOverrides:     containerOverrides,
+			ShellContainer:         shellContainer,
+			ShellPath:              shellPath,
+			ShellPreference:        shellPreference,
+			WaitTimeout:            waitTimeout,
+			DebugTools:             debugTools,
+			DebugToolsImage:        debugToolsImage,
 			PreserveInitContainers: preserveInitContainers,
+			KeepInitContainers:     keepInitContainers,
+			DropInitContainers:     dropInitContainers,
+			Creator:                creator,
+			Note:                   note,
+			DryRun:                 dryRun,
+			Output:                 output,
+			Diff:                   diff,
+			TTL:                    ttl,
+			Metadata:               metadata,
+			KeepLabels:             keepLabels,
 		}
 
 		// If available, duplicate the resource provided as argument
@@ -109,49 +256,281 @@ func newDuplicateCmd(newDuplicator duplicatorFactory, client core.Client, gvr sc
 	}
 }
 
+// newDryRunOptions returns the dry run strategy and the output format provided with the flags added by
+// addDryRunFlags, if the command has them. Printing the duplicated resource implies a client dry run,
+// unless a server one is requested.
+func newDryRunOptions(cmd *cobra.Command) (core.DryRunStrategy, string, error) {
+	if cmd.Flags().Lookup(flags.DRY_RUN) == nil {
+		return core.DryRunNone, "", nil
+	}
+	value, err := cmd.Flags().GetString(flags.DRY_RUN)
+	if err != nil {
+		return "", "", err
+	}
+	dryRun, err := core.ParseDryRunStrategy(value)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid --%s: %w", flags.DRY_RUN, err)
+	}
+	output, err := cmd.Flags().GetString(flags.OUTPUT)
+	if err != nil {
+		return "", "", err
+	}
+	switch output {
+	case "":
+	case "yaml", "json":
+		if dryRun == core.DryRunNone {
+			dryRun = core.DryRunClient
+		}
+	default:
+		return "", "", fmt.Errorf("unsupported output format %q: must be one of yaml or json", output)
+	}
+	return dryRun, output, nil
+}
+
+// addDryRunFlags adds the flags for previewing the duplicated resource without creating it.
+func addDryRunFlags(cmd *cobra.Command) {
+	cmd.Flags().String(
+		flags.DRY_RUN,
+		"none",
+		"Must be none, client or server. With client, only print the duplicated resource without sending it. "+
+			"With server, submit it to the API server without persisting it, so that it's validated and admitted.",
+	)
+	cmd.Flags().Lookup(flags.DRY_RUN).NoOptDefVal = string(core.DryRunClient)
+	cmd.Flags().StringP(
+		flags.OUTPUT,
+		"o",
+		"",
+		"Print the duplicated resource in the provided format, yaml or json, without creating it. "+
+			"Implies --dry-run=client unless --dry-run=server is provided.",
+	)
+}
+
 func addOverrideFlags(cmd *cobra.Command) {
+	cmd.Flags().String(
+		flags.NAME,
+		"",
+		"Name of the duplicated resource. If omitted, the name is generated from --name-template.",
+	)
+	cmd.Flags().Duration(
+		flags.TTL,
+		0,
+		"Time to live of the duplicated resource (e.g. 2h), after which it's deleted by 'cleanup --expired'. "+
+			"Duplicated Pods are also terminated. If omitted, the resource doesn't expire.",
+	)
+	cmd.Flags().Bool(
+		flags.DIFF,
+		false,
+		"Print the differences between the original resource and the duplicated one once it's created.",
+	)
+	cmd.Flags().String(
+		flags.NOTE,
+		"",
+		"Free text recorded on the duplicated resource, describing why it was created (e.g. INC-1234).",
+	)
+	cmd.Flags().String(
+		flags.NAME_TEMPLATE,
+		core.DEFAULT_NAME_TEMPLATE,
+		"Go template of the name of the duplicated resource, with fields .Name and .Namespace of the original one. "+
+			"If the name is taken, a random suffix is appended. Long names are truncated with a hash.",
+	)
 	cmd.Flags().StringSlice(
 		flags.COMMAND_OVERRIDE,
 		[]string{"/bin/sh"},
-		"Override the command of each container in the Pod.",
+		"Override the command of each container in the Pod. With --debug-tools, the default is the shell of the debug tools.",
 	)
 	cmd.Flags().StringSlice(
 		flags.ARGS_OVERRIDE,
 		[]string{"-c", "trap 'exit 0' INT TERM KILL; while true; do sleep 1; done"},
 		"Override the command of each container in the Pod.",
 	)
+	cmd.Flags().Bool(
+		flags.KEEP_COMMAND,
+		false,
+		"Keep the original command and args of the containers, ignoring --command-override and --args-override.",
+	)
+	addPolicyFlag(cmd, flags.LIVENESS_PROBE, "liveness probes")
+	addPolicyFlag(cmd, flags.READINESS_PROBE, "readiness probes")
+	addPolicyFlag(cmd, flags.STARTUP_PROBE, "startup probes")
+	addPolicyFlag(cmd, flags.POST_START_HOOK, "postStart lifecycle hooks")
+	addPolicyFlag(cmd, flags.PRE_STOP_HOOK, "preStop lifecycle hooks")
 	cmd.Flags().Bool(
 		flags.INTERACTIVE_SHELL,
 		false,
 		"After duplicating the resource, launch an interactive shell in the duplicated Pod.",
 	)
+	cmd.Flags().StringP(
+		flags.CONTAINER,
+		"c",
+		"",
+		"Container where the interactive shell is launched. "+
+			"If omitted and the Pod has more than one container, you'll be prompted to select one.",
+	)
+	cmd.Flags().StringSlice(
+		flags.TARGET_CONTAINER,
+		nil,
+		"Containers targeted by the overrides, including init containers. The other ones keep their original command. "+
+			"If omitted, all the containers except init containers are targeted. Init containers other than native sidecars "+
+			"keep their original command, so that they can complete, but get the other overrides. Can be repeated.",
+	)
+	cmd.Flags().StringArray(
+		flags.CONTAINER_COMMAND,
+		nil,
+		"Override the command of a specific container, in the form NAME=COMMAND[,ARG...]. Can be repeated.",
+	)
+	cmd.Flags().StringArray(
+		flags.CONTAINER_ARGS,
+		nil,
+		"Override the args of a specific container, in the form NAME=ARG[,ARG...]. Can be repeated.",
+	)
+	cmd.Flags().String(
+		flags.SHELL_PATH,
+		"",
+		"Path of the interactive shell. If omitted, the shell is detected according to --shell-preference.",
+	)
+	cmd.Flags().StringSlice(
+		flags.SHELL_PREFERENCE,
+		[]string{"bash", "ash", "sh"},
+		"Shells looked up in the duplicated Pod, in order of preference. Both names and absolute paths are supported.",
+	)
+	cmd.Flags().Duration(
+		flags.WAIT_TIMEOUT,
+		60*time.Second,
+		"Maximum time to wait for the duplicated Pod to be created and to be running before opening the shell.",
+	)
+	cmd.Flags().Bool(
+		flags.DEBUG_TOOLS,
+		false,
+		"Inject debug tools in the duplicated Pod, so that images without a shell (e.g. distroless) can be kept idle "+
+			"and inspected. The tools are added to the PATH of the containers.",
+	)
+	cmd.Flags().String(
+		flags.DEBUG_TOOLS_IMAGE,
+		core.DEBUG_TOOLS_IMAGE,
+		"Image providing the debug tools. Its statically linked binaries in /bin are copied to the duplicated Pod.",
+	)
 	cmd.Flags().Bool(
 		flags.PRESERVE_INIT_CONTAINERS,
 		false,
-		"Preserve the init containers in the duplicated Pod.",
+		"Preserve the init containers in the duplicated Pod. Native sidecars are always preserved.",
+	)
+	cmd.Flags().StringSlice(
+		flags.KEEP_INIT_CONTAINER,
+		nil,
+		"Init containers to preserve in the duplicated Pod. Can be repeated.",
+	)
+	cmd.Flags().StringSlice(
+		flags.DROP_INIT_CONTAINER,
+		nil,
+		"Init containers to remove from the duplicated Pod, including native sidecars. Can be repeated.",
+	)
+	cmd.Flags().Bool(
+		flags.KEEP_LABELS,
+		false,
+		"Keep the original selector and Pod labels. "+
+			"The duplicated Pods will be selected by the Services of the original resource.",
+	)
+	cmd.Flags().String(
+		flags.METADATA,
+		string(core.MetadataCopyAll),
+		"Labels and annotations copied from the original resource: all (except the denied ones) "+
+			"or none (except the allowed ones). Pod labels are only copied with --keep-labels or if allowed.",
+	)
+	cmd.Flags().StringSlice(
+		flags.METADATA_ALLOW,
+		nil,
+		"Globs of the labels and annotations always copied, unless denied. They take precedence over the presets.",
+	)
+	cmd.Flags().StringSlice(
+		flags.METADATA_DENY,
+		nil,
+		"Globs of the labels and annotations never copied.",
+	)
+	cmd.Flags().StringSlice(
+		flags.METADATA_PRESET,
+		core.DefaultMetadataPresets,
+		fmt.Sprintf(
+			"Built-in presets of labels and annotations that are not copied, unless allowed. Available presets: %s.",
+			strings.Join(core.MetadataPresets(), ", "),
+		),
 	)
 }
 
-func renderDuplicatedObjects(duplicatedObjs []core.DuplicatedObject) {
-	headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
-	defaultStyle := lipgloss.NewStyle().Padding(0, 1)
-	t := table.New().Border(lipgloss.HiddenBorder()).
-		StyleFunc(func(row, col int) lipgloss.Style {
-			switch row {
-			case 0:
-				return headerStyle
-			default:
-				return defaultStyle
-			}
-		}).
-		Headers("Namespace", "Kind", "Name", "Age")
-	for _, obj := range duplicatedObjs {
-		t.Row(
-			obj.Namespace,
-			obj.ObjectKind.GroupVersionKind().Kind,
-			obj.Name,
-			utils.FormatAge(obj.CreationTimestamp),
-		)
+func addPolicyFlag(cmd *cobra.Command, name string, description string) {
+	cmd.Flags().StringArray(
+		name,
+		nil,
+		fmt.Sprintf(
+			"Policy of the %s: keep, remove or replace with a trivial one that always succeeds, "+
+				"which requires --debug-tools (otherwise they are removed). "+
+				"Provide POLICY for the targeted containers, or CONTAINER=POLICY for a specific one. Can be repeated. "+
+				"By default, they are removed from the containers whose command is overridden.",
+			description,
+		),
+	)
+}
+
+// newMetadataPolicy returns the metadata propagation policy provided with the flags.
+func newMetadataPolicy(cmd *cobra.Command) (core.MetadataPolicy, error) {
+	mode, err := cmd.Flags().GetString(flags.METADATA)
+	if err != nil {
+		return core.MetadataPolicy{}, err
+	}
+	allow, err := cmd.Flags().GetStringSlice(flags.METADATA_ALLOW)
+	if err != nil {
+		return core.MetadataPolicy{}, err
+	}
+	deny, err := cmd.Flags().GetStringSlice(flags.METADATA_DENY)
+	if err != nil {
+		return core.MetadataPolicy{}, err
+	}
+	presets, err := cmd.Flags().GetStringSlice(flags.METADATA_PRESET)
+	if err != nil {
+		return core.MetadataPolicy{}, err
+	}
+	policy := core.MetadataPolicy{
+		Mode:    core.MetadataMode(mode),
+		Allow:   allow,
+		Deny:    deny,
+		Presets: presets,
+	}
+	return policy, policy.Validate()
+}
+
+// parseContainerOverrides parses the overrides of specific containers, provided in the form NAME=VALUE[,VALUE...].
+func parseContainerOverrides(commands, args []string) (map[string]core.ContainerOverride, error) {
+	overrides := make(map[string]core.ContainerOverride)
+	for _, c := range commands {
+		name, values, err := parseContainerValues(flags.CONTAINER_COMMAND, c)
+		if err != nil {
+			return nil, err
+		}
+		override := overrides[name]
+		override.Command = values
+		overrides[name] = override
+	}
+	for _, a := range args {
+		name, values, err := parseContainerValues(flags.CONTAINER_ARGS, a)
+		if err != nil {
+			return nil, err
+		}
+		override := overrides[name]
+		override.Args = values
+		overrides[name] = override
+	}
+	return overrides, nil
+}
+
+func parseContainerValues(flag, value string) (string, []string, error) {
+	name, csvValues, found := strings.Cut(value, "=")
+	if !found || name == "" {
+		return "", nil, fmt.Errorf("invalid --%s %q, expected NAME=VALUE[,VALUE...]", flag, value)
+	}
+	if csvValues == "" {
+		return name, []string{}, nil
+	}
+	values, err := csv.NewReader(strings.NewReader(csvValues)).Read()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid --%s %q: %w", flag, value, err)
 	}
-	fmt.Print(t.Render() + "\n")
+	return name, values, nil
 }
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"testing"
+)
+
+func Test_ParseContainerOverrides(t *testing.T) {
+	overrides, err := parseContainerOverrides(
+		[]string{`app=/bin/sh,-c,"echo a,b"`, "sidecar="},
+		[]string{"app=--verbose"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]core.ContainerOverride{
+		"app": {
+			Command: []string{"/bin/sh", "-c", "echo a,b"},
+			Args:    []string{"--verbose"},
+		},
+		"sidecar": {Command: []string{}},
+	}, overrides)
+}
+
+func Test_ParseContainerOverrides_Invalid(t *testing.T) {
+	_, err := parseContainerOverrides([]string{"/bin/sh"}, nil)
+	assert.EqualError(t, err, `invalid --container-command "/bin/sh", expected NAME=VALUE[,VALUE...]`)
+}
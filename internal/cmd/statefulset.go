@@ -46,5 +46,6 @@ func NewStatefulSetCmd(duplicator core.Duplicator, client core.Client) *cobra.Co
 		},
 	}
 	addOverrideFlags(deployCmd)
+	addDryRunFlags(deployCmd)
 	return deployCmd
 }
@@ -0,0 +1,262 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "+"
+	ChangeRemoved  ChangeType = "-"
+	ChangeModified ChangeType = "~"
+)
+
+// Change is a difference between a source resource and its duplicate.
+type Change struct {
+	Type ChangeType
+	// Path is the path of the changed field in the duplicate (e.g. spec.containers[app].command).
+	Path string
+	// Source is the value in the source, nil if the field has been added.
+	Source any
+	// Duplicate is the value in the duplicate, nil if the field has been removed.
+	Duplicate any
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, formatValue(c.Duplicate))
+	case ChangeRemoved:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, formatValue(c.Source))
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Type, c.Path, formatValue(c.Source), formatValue(c.Duplicate))
+	}
+}
+
+func formatValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// ignoredKeys are the labels set by the API server on every copy, such as the labels of the Job controller,
+// which are not changes made by duplik8s.
+var ignoredKeys = []string{
+	"controller-uid",
+	"job-name",
+	"batch.kubernetes.io/controller-uid",
+	"batch.kubernetes.io/job-name",
+}
+
+// ignoredLabelPaths are the paths of the label maps where the API server sets the ignored keys.
+// The same keys elsewhere (e.g. in a node selector or in the annotations) are set by the user and compared.
+var ignoredLabelPaths = [][]string{
+	{"metadata", "labels"},
+	{"spec", "selector", "matchLabels"},
+	{"spec", "selector"},
+	{"spec", "template", "metadata", "labels"},
+}
+
+// withoutIgnoredKeys removes the ignored keys from the label maps of the provided view,
+// together with the maps left empty by their removal.
+func withoutIgnoredKeys(view map[string]any) {
+	for _, path := range ignoredLabelPaths {
+		labels, found, err := unstructured.NestedMap(view, path...)
+		if err != nil || !found {
+			continue
+		}
+		count := len(labels)
+		for _, key := range ignoredKeys {
+			delete(labels, key)
+		}
+		if len(labels) == count {
+			continue
+		}
+		_ = unstructured.SetNestedMap(view, labels, path...)
+		for i := len(path); i > 0; i-- {
+			if parent, _, _ := unstructured.NestedMap(view, path[:i]...); len(parent) > 0 {
+				break
+			}
+			unstructured.RemoveNestedField(view, path[:i]...)
+		}
+	}
+}
+
+// comparedField is a field of the source compared with a field of the duplicate.
+type comparedField struct {
+	source    []string
+	duplicate []string
+}
+
+// comparedFields returns the fields compared between a source of the provided kind and its duplicate.
+// Duplicates of DaemonSets are Pods and duplicates of CronJobs are Jobs, so their templates are compared.
+func comparedFields(sourceKind, duplicateKind string) []comparedField {
+	var template []string
+	switch {
+	case sourceKind == "DaemonSet" && duplicateKind == "Pod":
+		template = []string{"spec", "template"}
+	case sourceKind == "CronJob" && duplicateKind == "Job":
+		template = []string{"spec", "jobTemplate"}
+	}
+	fields := make([]comparedField, 0, 3)
+	for _, path := range [][]string{{"metadata", "labels"}, {"metadata", "annotations"}, {"spec"}} {
+		fields = append(fields, comparedField{source: append(slices.Clone(template), path...), duplicate: path})
+	}
+	return fields
+}
+
+// ComparableViews returns the parts of the source and of its duplicate that can be compared:
+// their labels, annotations and spec, with the same structure. The annotations of duplik8s
+// and the labels set by the API server (see ignoredKeys) are excluded.
+func ComparableViews(source, duplicate *unstructured.Unstructured) (map[string]any, map[string]any) {
+	sourceView := make(map[string]any)
+	duplicateView := make(map[string]any)
+	for _, field := range comparedFields(source.GetKind(), duplicate.GetKind()) {
+		if value, found, _ := unstructured.NestedFieldCopy(source.Object, field.source...); found {
+			_ = unstructured.SetNestedField(sourceView, value, field.duplicate...)
+		}
+		if value, found, _ := unstructured.NestedFieldCopy(duplicate.Object, field.duplicate...); found {
+			_ = unstructured.SetNestedField(duplicateView, value, field.duplicate...)
+		}
+	}
+	for _, view := range []map[string]any{sourceView, duplicateView} {
+		withoutIgnoredKeys(view)
+		annotations, _, _ := unstructured.NestedStringMap(view, "metadata", "annotations")
+		for key := range annotations {
+			if strings.HasPrefix(key, duplik8sAnnotationPrefix) {
+				delete(annotations, key)
+			}
+		}
+		if len(annotations) == 0 {
+			unstructured.RemoveNestedField(view, "metadata", "annotations")
+		} else {
+			_ = unstructured.SetNestedStringMap(view, annotations, "metadata", "annotations")
+		}
+		if metadata, _, _ := unstructured.NestedMap(view, "metadata"); len(metadata) == 0 {
+			unstructured.RemoveNestedField(view, "metadata")
+		}
+	}
+	return sourceView, duplicateView
+}
+
+// Compare returns the changes made to the source in its duplicate, sorted by path.
+func Compare(source, duplicate *unstructured.Unstructured) []Change {
+	sourceView, duplicateView := ComparableViews(source, duplicate)
+	return compareValues("", sourceView, duplicateView, nil)
+}
+
+func compareValues(path string, source, duplicate any, changes []Change) []Change {
+	sourceMap, sourceIsMap := source.(map[string]any)
+	duplicateMap, duplicateIsMap := duplicate.(map[string]any)
+	if sourceIsMap && duplicateIsMap {
+		keys := make([]string, 0, len(sourceMap)+len(duplicateMap))
+		for key := range sourceMap {
+			keys = append(keys, key)
+		}
+		for key := range duplicateMap {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range slices.Compact(keys) {
+			changes = compareField(joinPath(path, key), sourceMap, duplicateMap, key, changes)
+		}
+		return changes
+	}
+
+	sourceItems, sourceIsNamed := namedItems(source)
+	duplicateItems, duplicateIsNamed := namedItems(duplicate)
+	if sourceIsNamed && duplicateIsNamed {
+		names := make([]string, 0, len(sourceItems)+len(duplicateItems))
+		for name := range sourceItems {
+			names = append(names, name)
+		}
+		for name := range duplicateItems {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range slices.Compact(names) {
+			changes = compareField(fmt.Sprintf("%s[%s]", path, name), sourceItems, duplicateItems, name, changes)
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(source, duplicate) {
+		changes = append(changes, Change{Type: ChangeModified, Path: path, Source: source, Duplicate: duplicate})
+	}
+	return changes
+}
+
+func compareField(path string, source, duplicate map[string]any, key string, changes []Change) []Change {
+	sourceValue, inSource := source[key]
+	duplicateValue, inDuplicate := duplicate[key]
+	switch {
+	case !inSource:
+		return append(changes, Change{Type: ChangeAdded, Path: path, Duplicate: duplicateValue})
+	case !inDuplicate:
+		return append(changes, Change{Type: ChangeRemoved, Path: path, Source: sourceValue})
+	default:
+		return compareValues(path, sourceValue, duplicateValue, changes)
+	}
+}
+
+// namedItems returns the items of the provided list by name, if it's a list of objects with unique names
+// (e.g. containers, volumes, env variables).
+func namedItems(value any) (map[string]any, bool) {
+	list, ok := value.([]any)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	items := make(map[string]any, len(list))
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := obj["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		if _, duplicated := items[name]; duplicated {
+			return nil, false
+		}
+		items[name] = item
+	}
+	return items, true
+}
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func joinPath(path, key string) string {
+	if !identifierRegexp.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func Test_Compare(t *testing.T) {
+	source := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":   "nginx",
+			"labels": map[string]any{"app": "nginx", "app.kubernetes.io/name": "nginx"},
+		},
+		"spec": map[string]any{
+			"nodeName": "node-1",
+			"containers": []any{
+				map[string]any{
+					"name":          "nginx",
+					"image":         "nginx",
+					"command":       []any{"nginx"},
+					"livenessProbe": map[string]any{"httpGet": map[string]any{"path": "/"}},
+				},
+			},
+		},
+	}}
+	duplicate := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":   "nginx-duplik8ted",
+			"labels": map[string]any{"app": "nginx", LABEL_DUPLICATED: "true"},
+			"annotations": map[string]any{
+				ANNOTATION_SOURCE_NAME: "nginx",
+			},
+		},
+		"spec": map[string]any{
+			"nodeName": "node-1",
+			"containers": []any{
+				map[string]any{
+					"name":    "nginx",
+					"image":   "nginx",
+					"command": []any{"sh"},
+				},
+			},
+		},
+	}}
+
+	changes := Compare(source, duplicate)
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		`- metadata.labels["app.kubernetes.io/name"]: "nginx"`,
+		`+ metadata.labels["telemaco019.github.com/duplik8ted"]: "true"`,
+		`~ spec.containers[nginx].command: ["nginx"] -> ["sh"]`,
+		`- spec.containers[nginx].livenessProbe: {"httpGet":{"path":"/"}}`,
+	}, lines)
+}
+
+func Test_Compare_CronJob(t *testing.T) {
+	source := &unstructured.Unstructured{Object: map[string]any{
+		"kind": "CronJob",
+		"spec": map[string]any{
+			"schedule": "* * * * *",
+			"jobTemplate": map[string]any{
+				"spec": map[string]any{"backoffLimit": int64(6)},
+			},
+		},
+	}}
+	duplicate := &unstructured.Unstructured{Object: map[string]any{
+		"kind": "Job",
+		"spec": map[string]any{
+			"backoffLimit": int64(6),
+			"suspend":      true,
+			"selector": map[string]any{
+				"matchLabels": map[string]any{"batch.kubernetes.io/controller-uid": "uid"},
+			},
+		},
+	}}
+	// the selector set by the API server is ignored
+	changes := Compare(source, duplicate)
+	assert.Equal(t, []Change{
+		{Type: ChangeAdded, Path: "spec.suspend", Duplicate: true},
+	}, changes)
+}
+
+func Test_Compare_IgnoredKeysOnlyInLabels(t *testing.T) {
+	newJob := func(name, uid string) *unstructured.Unstructured {
+		labels := map[string]any{"controller-uid": uid, "job-name": name, "app": "report"}
+		return &unstructured.Unstructured{Object: map[string]any{
+			"kind": "Job",
+			"metadata": map[string]any{
+				"name":        name,
+				"labels":      labels,
+				"annotations": map[string]any{"job-name": name},
+			},
+			"spec": map[string]any{
+				"selector": map[string]any{"matchLabels": map[string]any{"controller-uid": uid}},
+				"template": map[string]any{
+					"metadata": map[string]any{"labels": labels},
+					"spec": map[string]any{
+						"nodeSelector": map[string]any{"controller-uid": uid},
+					},
+				},
+			},
+		}}
+	}
+
+	changes := Compare(newJob("report", "uid-1"), newJob("report-duplik8ted", "uid-2"))
+	assert.Equal(t, []Change{
+		{Type: ChangeModified, Path: `metadata.annotations["job-name"]`, Source: "report", Duplicate: "report-duplik8ted"},
+		{Type: ChangeModified, Path: `spec.template.spec.nodeSelector["controller-uid"]`, Source: "uid-1", Duplicate: "uid-2"},
+	}, changes)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+const (
+	// DEBUG_TOOLS_IMAGE is the default image providing the debug tools. Its binaries are statically linked,
+	// so they can run in any image.
+	DEBUG_TOOLS_IMAGE = "busybox:1.37-musl"
+	// DEBUG_TOOLS_DIR is the directory where the debug tools are mounted in the duplicated containers.
+	DEBUG_TOOLS_DIR = "/duplik8s-tools"
+	// DEBUG_TOOLS_VOLUME is the name of the volume sharing the debug tools with the duplicated containers.
+	DEBUG_TOOLS_VOLUME = "duplik8s-tools"
+	// DEBUG_TOOLS_CONTAINER is the name of the init container copying the debug tools.
+	DEBUG_TOOLS_CONTAINER = "duplik8s-tools"
+	// DEBUG_TOOLS_USER is the non-root user copying the debug tools.
+	DEBUG_TOOLS_USER = 65534
+)
+
+// defaultPath is the PATH of the duplicated containers that don't set it explicitly.
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// DebugToolsPath returns the PATH of a container having the debug tools mounted.
+// The tools are appended to the provided PATH, so that the binaries of the image take precedence.
+func DebugToolsPath(path string) string {
+	if path == "" {
+		path = defaultPath
+	}
+	return path + ":" + DEBUG_TOOLS_DIR
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// Diff returns the unified diff between the YAML of the comparable views of the source and of its duplicate,
+// see ComparableViews, or an empty string if they don't differ.
+func Diff(source, duplicate *unstructured.Unstructured) (string, error) {
+	sourceView, duplicateView := ComparableViews(source, duplicate)
+	sourceYAML, err := yaml.Marshal(sourceView)
+	if err != nil {
+		return "", err
+	}
+	duplicateYAML, err := yaml.Marshal(duplicateView)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(sourceYAML)),
+		B:        splitLines(string(duplicateYAML)),
+		FromFile: fmt.Sprintf("%s/%s (source)", source.GetKind(), source.GetName()),
+		ToFile:   fmt.Sprintf("%s/%s (duplicate)", duplicate.GetKind(), duplicate.GetName()),
+		Context:  3,
+	})
+}
+
+// splitLines splits the provided text into lines, keeping their line endings.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
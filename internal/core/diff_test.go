@@ -0,0 +1,89 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newDiffPod(name string, labels map[string]any, command ...any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":        name,
+			"labels":      labels,
+			"annotations": map[string]any{ANNOTATION_SOURCE_NAME: "nginx"},
+		},
+		"spec": map[string]any{
+			"containers": []any{map[string]any{"name": "nginx", "command": command}},
+		},
+	}}
+}
+
+func Test_Diff(t *testing.T) {
+	source := newDiffPod("nginx", map[string]any{"app": "nginx", "job-name": "nginx"}, "nginx")
+	duplicate := newDiffPod("nginx-duplik8ted", map[string]any{"app": "nginx", "job-name": "nginx-duplik8ted"}, "sh")
+
+	diff, err := Diff(source, duplicate)
+	assert.NoError(t, err)
+	assert.Equal(t, `--- Pod/nginx (source)
++++ Pod/nginx-duplik8ted (duplicate)
+@@ -4,5 +4,5 @@
+ spec:
+   containers:
+   - command:
+-    - nginx
++    - sh
+     name: nginx
+`, diff)
+}
+
+func Test_Diff_NoChanges(t *testing.T) {
+	diff, err := Diff(newDiffPod("nginx", nil, "nginx"), newDiffPod("nginx-duplik8ted", nil, "nginx"))
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func Test_Diff_IgnoredKeysOnlyInLabels(t *testing.T) {
+	newJob := func(name, uid string) *unstructured.Unstructured {
+		labels := map[string]any{"batch.kubernetes.io/controller-uid": uid, "batch.kubernetes.io/job-name": name}
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata":   map[string]any{"name": name, "labels": labels},
+			"spec": map[string]any{
+				"selector": map[string]any{"matchLabels": map[string]any{"batch.kubernetes.io/controller-uid": uid}},
+				"template": map[string]any{
+					"metadata": map[string]any{"labels": labels},
+					"spec": map[string]any{
+						// set by the user, not by the API server
+						"nodeSelector": map[string]any{"job-name": name},
+					},
+				},
+			},
+		}}
+	}
+
+	diff, err := Diff(newJob("report", "uid-1"), newJob("report-duplik8ted", "uid-2"))
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "-        job-name: report\n+        job-name: report-duplik8ted\n")
+	assert.NotContains(t, diff, "controller-uid")
+	assert.NotContains(t, diff, "batch.kubernetes.io/job-name")
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import "fmt"
+
+// DryRunStrategy defines whether the duplicated resource is actually created.
+type DryRunStrategy string
+
+const (
+	// DryRunNone creates the duplicated resource.
+	DryRunNone DryRunStrategy = ""
+	// DryRunClient doesn't send the duplicated resource to the API server.
+	DryRunClient DryRunStrategy = "client"
+	// DryRunServer submits the duplicated resource to the API server without persisting it, so that
+	// it goes through validation and admission.
+	DryRunServer DryRunStrategy = "server"
+)
+
+// ParseDryRunStrategy parses the provided dry run strategy. "none" is accepted as an alias of DryRunNone.
+func ParseDryRunStrategy(value string) (DryRunStrategy, error) {
+	switch s := DryRunStrategy(value); s {
+	case DryRunNone, DryRunClient, DryRunServer:
+		return s, nil
+	case "none":
+		return DryRunNone, nil
+	default:
+		return "", fmt.Errorf("invalid dry run strategy %q, must be one of none, %s, %s", value, DryRunClient, DryRunServer)
+	}
+}
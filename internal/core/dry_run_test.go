@@ -0,0 +1,37 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ParseDryRunStrategy(t *testing.T) {
+	for value, expected := range map[string]DryRunStrategy{
+		"":       DryRunNone,
+		"none":   DryRunNone,
+		"client": DryRunClient,
+		"server": DryRunServer,
+	} {
+		strategy, err := ParseDryRunStrategy(value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, strategy)
+	}
+	_, err := ParseDryRunStrategy("true")
+	assert.EqualError(t, err, `invalid dry run strategy "true", must be one of none, client, server`)
+}
@@ -16,6 +16,58 @@
 
 package core
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// duplik8sAnnotationPrefix is the prefix of the annotations set by duplik8s on the duplicated resources.
+const duplik8sAnnotationPrefix = "telemaco019.github.com/duplik8s-"
+
 const (
 	LABEL_DUPLICATED = "telemaco019.github.com/duplik8ted"
+	// LABEL_INSTANCE identifies the Pods of a duplicated resource.
+	LABEL_INSTANCE = "telemaco019.github.com/duplik8s-instance"
+	// LABEL_SESSION identifies the Leases of the shell sessions on the duplicated resources.
+	LABEL_SESSION = "telemaco019.github.com/duplik8s-session"
+	// ANNOTATION_EXPIRES_AT is the time, in RFC 3339 format, after which the duplicated resource can be deleted.
+	ANNOTATION_EXPIRES_AT = "telemaco019.github.com/duplik8s-expires-at"
+	// ANNOTATION_SESSION_LEASE is the name of the Lease renewed while the shell session on the duplicated resource is alive.
+	ANNOTATION_SESSION_LEASE = "telemaco019.github.com/duplik8s-session-lease"
+	// ANNOTATION_SOURCE_API_VERSION is the API version of the resource the duplicated resource was copied from.
+	ANNOTATION_SOURCE_API_VERSION = "telemaco019.github.com/duplik8s-source-api-version"
+	// ANNOTATION_SOURCE_KIND is the kind of the resource the duplicated resource was copied from.
+	ANNOTATION_SOURCE_KIND = "telemaco019.github.com/duplik8s-source-kind"
+	// ANNOTATION_SOURCE_NAME is the name of the resource the duplicated resource was copied from.
+	ANNOTATION_SOURCE_NAME = "telemaco019.github.com/duplik8s-source-name"
+	// ANNOTATION_SOURCE_UID is the UID of the resource the duplicated resource was copied from.
+	ANNOTATION_SOURCE_UID = "telemaco019.github.com/duplik8s-source-uid"
+	// ANNOTATION_SOURCE_RESOURCE_VERSION is the resource version of the source when it was copied.
+	ANNOTATION_SOURCE_RESOURCE_VERSION = "telemaco019.github.com/duplik8s-source-resource-version"
+	// ANNOTATION_OPTIONS are the options, in JSON format, the duplicated resource was created with.
+	ANNOTATION_OPTIONS = "telemaco019.github.com/duplik8s-options"
+	// ANNOTATION_CREATOR is the user who created the duplicated resource.
+	ANNOTATION_CREATOR = "telemaco019.github.com/duplik8s-creator"
+	// ANNOTATION_NOTE is a free text describing why the duplicated resource was created (e.g. an incident ID).
+	ANNOTATION_NOTE = "telemaco019.github.com/duplik8s-note"
 )
+
+// NewInstanceLabels returns the labels selecting the Pods of the duplicated resource with the provided name.
+func NewInstanceLabels(name string) map[string]string {
+	return map[string]string{
+		LABEL_INSTANCE: name,
+	}
+}
+
+// ParseExpiresAt returns the expiration time recorded in the provided annotations, or nil if there is none.
+func ParseExpiresAt(annotations map[string]string) *metav1.Time {
+	value, ok := annotations[ANNOTATION_EXPIRES_AT]
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
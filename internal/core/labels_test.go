@@ -0,0 +1,35 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_ParseExpiresAt(t *testing.T) {
+	expiresAt := ParseExpiresAt(map[string]string{ANNOTATION_EXPIRES_AT: "2025-06-01T10:00:00Z"})
+	assert.Equal(t, time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), expiresAt.Time.UTC())
+	assert.Nil(t, ParseExpiresAt(map[string]string{ANNOTATION_EXPIRES_AT: "tomorrow"}))
+	assert.Nil(t, ParseExpiresAt(nil))
+
+	obj := DuplicatedObject{ExpiresAt: expiresAt}
+	assert.True(t, obj.Expired(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)))
+	assert.False(t, obj.Expired(time.Date(2025, 6, 1, 9, 59, 0, 0, time.UTC)))
+	assert.False(t, DuplicatedObject{}.Expired(time.Now()))
+}
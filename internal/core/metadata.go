@@ -0,0 +1,165 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// MetadataMode defines which labels and annotations of the original resource are copied to the duplicated one.
+type MetadataMode string
+
+const (
+	// MetadataCopyAll copies all the labels and annotations, except the denied ones.
+	MetadataCopyAll MetadataMode = "all"
+	// MetadataCopyNone copies only the allowed labels and annotations.
+	MetadataCopyNone MetadataMode = "none"
+)
+
+// metadataPresets maps the name of the built-in presets to the globs of the labels and annotations they strip.
+var metadataPresets = map[string][]string{
+	// ownership and tracking metadata of GitOps tools, so that they never adopt or prune the duplicates
+	"gitops": {
+		"argocd.argoproj.io/*",
+		"app.kubernetes.io/instance",
+		"kustomize.toolkit.fluxcd.io/*",
+		"helm.toolkit.fluxcd.io/*",
+	},
+	// release metadata of Helm
+	"helm": {
+		"meta.helm.sh/*",
+		"helm.sh/*",
+		"app.kubernetes.io/managed-by",
+	},
+	// metadata of kubectl apply
+	"kubectl": {
+		"kubectl.kubernetes.io/last-applied-configuration",
+	},
+	// metadata set by the built-in controllers on the objects they manage
+	"controllers": {
+		"pod-template-hash",
+		"controller-revision-hash",
+		"pod-template-generation",
+		"statefulset.kubernetes.io/*",
+		"apps.kubernetes.io/*",
+		"deployment.kubernetes.io/*",
+		"batch.kubernetes.io/*",
+		"controller-uid",
+		"job-name",
+	},
+}
+
+// DefaultMetadataPresets are the presets applied by default.
+var DefaultMetadataPresets = []string{"gitops", "helm", "kubectl", "controllers"}
+
+// MetadataPresets returns the names of the built-in presets.
+func MetadataPresets() []string {
+	return slices.Sorted(maps.Keys(metadataPresets))
+}
+
+// MetadataPolicy defines how the labels and the annotations of the original resource
+// are propagated to the duplicated one.
+type MetadataPolicy struct {
+	// Mode defines whether the metadata is copied by default. If empty, all the metadata is copied.
+	Mode MetadataMode `json:"mode,omitempty"`
+	// Allow are the globs of the keys always copied, unless denied. They take precedence over the presets.
+	Allow []string `json:"allow,omitempty"`
+	// Deny are the globs of the keys never copied.
+	Deny []string `json:"deny,omitempty"`
+	// Presets are the names of the built-in presets whose keys are not copied, unless allowed.
+	Presets []string `json:"presets,omitempty"`
+}
+
+// Validate returns an error if the policy is not valid.
+func (p MetadataPolicy) Validate() error {
+	switch p.Mode {
+	case "", MetadataCopyAll, MetadataCopyNone:
+	default:
+		return fmt.Errorf("invalid metadata mode %q, must be one of %s, %s", p.Mode, MetadataCopyAll, MetadataCopyNone)
+	}
+	for _, preset := range p.Presets {
+		if _, ok := metadataPresets[preset]; !ok {
+			return fmt.Errorf("unknown metadata preset %q, available presets are %v", preset, MetadataPresets())
+		}
+	}
+	for _, glob := range slices.Concat(p.Allow, p.Deny) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid metadata glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// Filter returns the labels or annotations that are copied to the duplicated resource.
+func (p MetadataPolicy) Filter(metadata map[string]string) map[string]string {
+	filtered := make(map[string]string)
+	for k, v := range metadata {
+		if p.copies(k) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// Allowed returns the labels or annotations that are explicitly allowed, regardless of the mode.
+func (p MetadataPolicy) Allowed(metadata map[string]string) map[string]string {
+	allowed := make(map[string]string)
+	for k, v := range metadata {
+		if matchAny(p.Allow, k) && !matchAny(p.Deny, k) {
+			allowed[k] = v
+		}
+	}
+	return allowed
+}
+
+func (p MetadataPolicy) copies(key string) bool {
+	if matchAny(p.Deny, key) {
+		return false
+	}
+	if matchAny(p.Allow, key) {
+		return true
+	}
+	if p.Mode == MetadataCopyNone {
+		return false
+	}
+	for _, preset := range p.Presets {
+		if matchAny(metadataPresets[preset], key) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAny returns true if the key matches any of the globs. Unlike path.Match,
+// wildcards also match the "/" separating the prefix of the keys from their name.
+func matchAny(globs []string, key string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(escapeSeparator(glob), escapeSeparator(key)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeSeparator replaces the "/" separator with a character not allowed in label and annotation keys.
+func escapeSeparator(s string) string {
+	return strings.ReplaceAll(s, "/", "|")
+}
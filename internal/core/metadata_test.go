@@ -0,0 +1,79 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var originalMetadata = map[string]string{
+	"app":                          "web",
+	"app.kubernetes.io/instance":   "web-prod",
+	"argocd.argoproj.io/sync-wave": "1",
+	"meta.helm.sh/release-name":    "web",
+	"prometheus.io/scrape":         "true",
+	"sidecar.istio.io/inject":      "true",
+	"pod-template-hash":            "5d8f7c",
+}
+
+func Test_MetadataPolicy_Presets(t *testing.T) {
+	policy := MetadataPolicy{Presets: DefaultMetadataPresets}
+	assert.Equal(t, map[string]string{
+		"app":                     "web",
+		"prometheus.io/scrape":    "true",
+		"sidecar.istio.io/inject": "true",
+	}, policy.Filter(originalMetadata))
+}
+
+func Test_MetadataPolicy_AllowAndDeny(t *testing.T) {
+	policy := MetadataPolicy{
+		Allow:   []string{"app.kubernetes.io/*", "prometheus.io/*"},
+		Deny:    []string{"prometheus.io/scrape", "sidecar.*"},
+		Presets: DefaultMetadataPresets,
+	}
+	assert.Equal(t, map[string]string{
+		"app":                        "web",
+		"app.kubernetes.io/instance": "web-prod",
+	}, policy.Filter(originalMetadata))
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/instance": "web-prod",
+	}, policy.Allowed(originalMetadata))
+}
+
+func Test_MetadataPolicy_CopyNone(t *testing.T) {
+	policy := MetadataPolicy{Mode: MetadataCopyNone, Allow: []string{"sidecar.istio.io/*"}}
+	assert.Equal(t, map[string]string{
+		"sidecar.istio.io/inject": "true",
+	}, policy.Filter(originalMetadata))
+	assert.Empty(t, MetadataPolicy{Mode: MetadataCopyNone}.Filter(originalMetadata))
+}
+
+func Test_MetadataPolicy_Validate(t *testing.T) {
+	assert.NoError(t, MetadataPolicy{Mode: MetadataCopyAll, Presets: DefaultMetadataPresets}.Validate())
+	assert.EqualError(
+		t,
+		MetadataPolicy{Mode: "some"}.Validate(),
+		`invalid metadata mode "some", must be one of all, none`,
+	)
+	assert.EqualError(
+		t,
+		MetadataPolicy{Presets: []string{"flux"}}.Validate(),
+		`unknown metadata preset "flux", available presets are [controllers gitops helm kubectl]`,
+	)
+	assert.Error(t, MetadataPolicy{Deny: []string{"[a-"}}.Validate())
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"strings"
+	"text/template"
+)
+
+const (
+	// DEFAULT_NAME_TEMPLATE is the default template of the names of the duplicated resources.
+	DEFAULT_NAME_TEMPLATE = "{{ .Name }}-duplik8ted"
+	// MAX_NAME_LENGTH is the maximum length of the names of the duplicated resources,
+	// since they are used as label values.
+	MAX_NAME_LENGTH = validation.LabelValueMaxLength
+	// MAX_STATEFULSET_NAME_LENGTH is the maximum length of the names of the duplicated StatefulSets:
+	// the controller-revision-hash label of their Pods is made of the name followed by a hash of 11 characters.
+	MAX_STATEFULSET_NAME_LENGTH = validation.LabelValueMaxLength - 11
+	// nameCollisionRetries is the number of random suffixes tried when the name of the duplicate is already taken.
+	nameCollisionRetries = 5
+)
+
+// NameTemplateData is the data available in the template of the names of the duplicated resources.
+type NameTemplateData struct {
+	// Name is the name of the original resource.
+	Name string
+	// Namespace is the namespace of the original resource.
+	Namespace string
+}
+
+// ParseNameTemplate parses the template of the names of the duplicated resources.
+func ParseNameTemplate(text string) (*template.Template, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name template %q: %w", text, err)
+	}
+	return t, nil
+}
+
+// NewDuplicatedName returns the name of the duplicate of the provided resource, no longer than maxLength.
+//
+// The name provided in the options is used as is, otherwise the name is generated from the name template.
+// If a resource with the generated name already exists, a random suffix is appended to it.
+func NewDuplicatedName(
+	original DuplicableObject,
+	opts DuplicateOpts,
+	maxLength int,
+	exists func(name string) (bool, error),
+) (string, error) {
+	if opts.Name != "" {
+		if len(opts.Name) > maxLength {
+			return "", fmt.Errorf("name %q is longer than %d characters", opts.Name, maxLength)
+		}
+		return opts.Name, validateName(opts.Name)
+	}
+
+	text := opts.NameTemplate
+	if text == "" {
+		text = DEFAULT_NAME_TEMPLATE
+	}
+	t, err := ParseNameTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, NameTemplateData{Name: original.Name, Namespace: original.Namespace})
+	if err != nil {
+		return "", fmt.Errorf("invalid name template %q: %w", text, err)
+	}
+
+	name := TruncateName(buf.String(), maxLength)
+	for i := 0; ; i++ {
+		if err = validateName(name); err != nil {
+			return "", err
+		}
+		taken, err := exists(name)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return name, nil
+		}
+		if i == nameCollisionRetries {
+			return "", fmt.Errorf("no name available for the duplicate of %q", original.Name)
+		}
+		// allow concurrent duplicates of the same resource
+		name = TruncateName(buf.String(), maxLength-6) + "-" + rand.String(5)
+	}
+}
+
+// TruncateName truncates the provided name to maxLength characters. Truncated names end with
+// a hash of the full name, so that different names sharing the same prefix don't collide.
+func TruncateName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	hash := fmt.Sprintf("%08x", h.Sum32())
+	prefix := strings.TrimRight(name[:maxLength-len(hash)-1], "-.")
+	return prefix + "-" + hash
+}
+
+func validateName(name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", name, strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func notExists(string) (bool, error) {
+	return false, nil
+}
+
+func Test_NewDuplicatedName_Default(t *testing.T) {
+	name, err := NewDuplicatedName(DuplicableObject{Name: "nginx"}, DuplicateOpts{}, MAX_NAME_LENGTH, notExists)
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx-duplik8ted", name)
+}
+
+func Test_NewDuplicatedName_Template(t *testing.T) {
+	opts := DuplicateOpts{NameTemplate: "debug-{{ .Namespace }}-{{ .Name }}"}
+	name, err := NewDuplicatedName(DuplicableObject{Name: "nginx", Namespace: "web"}, opts, MAX_NAME_LENGTH, notExists)
+	assert.NoError(t, err)
+	assert.Equal(t, "debug-web-nginx", name)
+
+	opts = DuplicateOpts{NameTemplate: "{{ .Kind }}"}
+	_, err = NewDuplicatedName(DuplicableObject{Name: "nginx"}, opts, MAX_NAME_LENGTH, notExists)
+	assert.Error(t, err)
+}
+
+func Test_NewDuplicatedName_Explicit(t *testing.T) {
+	opts := DuplicateOpts{Name: "my-copy"}
+	name, err := NewDuplicatedName(DuplicableObject{Name: "nginx"}, opts, MAX_NAME_LENGTH, notExists)
+	assert.NoError(t, err)
+	assert.Equal(t, "my-copy", name)
+
+	opts = DuplicateOpts{Name: "My_Copy"}
+	_, err = NewDuplicatedName(DuplicableObject{Name: "nginx"}, opts, MAX_NAME_LENGTH, notExists)
+	assert.Error(t, err)
+}
+
+func Test_NewDuplicatedName_Collision(t *testing.T) {
+	taken := map[string]bool{"nginx-duplik8ted": true}
+	name, err := NewDuplicatedName(
+		DuplicableObject{Name: "nginx"},
+		DuplicateOpts{},
+		MAX_NAME_LENGTH,
+		func(name string) (bool, error) {
+			return taken[name], nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^nginx-duplik8ted-[a-z0-9]{5}$`, name)
+}
+
+func Test_NewDuplicatedName_Truncated(t *testing.T) {
+	original := DuplicableObject{Name: strings.Repeat("a", 60)}
+	name, err := NewDuplicatedName(original, DuplicateOpts{}, MAX_STATEFULSET_NAME_LENGTH, notExists)
+	assert.NoError(t, err)
+	assert.Len(t, name, MAX_STATEFULSET_NAME_LENGTH)
+	assert.Regexp(t, `^a+-[0-9a-f]{8}$`, name)
+
+	// names sharing the same prefix don't collide
+	other, err := NewDuplicatedName(
+		DuplicableObject{Name: strings.Repeat("a", 61)},
+		DuplicateOpts{},
+		MAX_STATEFULSET_NAME_LENGTH,
+		notExists,
+	)
+	assert.NoError(t, err)
+	assert.NotEqual(t, name, other)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OverridePolicy defines how a setting of the duplicated containers, such as a probe or a lifecycle hook,
+// is handled.
+type OverridePolicy string
+
+const (
+	// OverridePolicyAuto removes the setting if the command of the container is overridden, and keeps it otherwise.
+	OverridePolicyAuto OverridePolicy = ""
+	// OverridePolicyKeep keeps the original setting.
+	OverridePolicyKeep OverridePolicy = "keep"
+	// OverridePolicyRemove removes the setting.
+	OverridePolicyRemove OverridePolicy = "remove"
+	// OverridePolicyReplace replaces the setting with a trivial one that always succeeds. Since the trivial command
+	// is provided by the debug tools, the setting is removed if they are not injected.
+	OverridePolicyReplace OverridePolicy = "replace"
+)
+
+// OverridePolicies holds the override policies of a setting by container name.
+// The policy with an empty name applies to all the containers targeted by the overrides.
+type OverridePolicies map[string]OverridePolicy
+
+// For returns the policy of the container with the provided name.
+func (p OverridePolicies) For(container string, targeted bool) OverridePolicy {
+	if policy, ok := p[container]; ok {
+		return policy
+	}
+	if targeted {
+		return p[""]
+	}
+	return OverridePolicyKeep
+}
+
+// ParseOverridePolicies parses policies provided in the form POLICY or CONTAINER=POLICY.
+func ParseOverridePolicies(values []string) (OverridePolicies, error) {
+	policies := make(OverridePolicies)
+	for _, v := range values {
+		container, policy, found := strings.Cut(v, "=")
+		if !found {
+			container, policy = "", v
+		}
+		switch p := OverridePolicy(policy); p {
+		case OverridePolicyKeep, OverridePolicyRemove, OverridePolicyReplace:
+			policies[container] = p
+		default:
+			return nil, fmt.Errorf(
+				"invalid policy %q, must be one of %s, %s, %s",
+				v,
+				OverridePolicyKeep,
+				OverridePolicyRemove,
+				OverridePolicyReplace,
+			)
+		}
+	}
+	return policies, nil
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ParseOverridePolicies(t *testing.T) {
+	policies, err := ParseOverridePolicies([]string{"remove", "envoy=keep", "app=replace"})
+	assert.NoError(t, err)
+	assert.Equal(t, OverridePolicyRemove, policies.For("worker", true))
+	assert.Equal(t, OverridePolicyKeep, policies.For("envoy", true))
+	assert.Equal(t, OverridePolicyReplace, policies.For("app", false))
+	assert.Equal(t, OverridePolicyKeep, policies.For("worker", false))
+}
+
+func Test_ParseOverridePolicies_Invalid(t *testing.T) {
+	_, err := ParseOverridePolicies([]string{"app=drop"})
+	assert.EqualError(t, err, `invalid policy "app=drop", must be one of keep, remove, replace`)
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"time"
+)
+
+const (
+	// SESSION_LEASE_DURATION is the time after which a shell session that stopped renewing its lease is abandoned.
+	SESSION_LEASE_DURATION = 60 * time.Second
+	// SESSION_LEASE_RENEW_INTERVAL is the time between two renewals of the lease of a shell session.
+	SESSION_LEASE_RENEW_INTERVAL = SESSION_LEASE_DURATION / 4
+)
+
+// NewSessionLeaseName returns a random name for the Lease of a shell session.
+func NewSessionLeaseName() string {
+	return "duplik8s-session-" + rand.String(8)
+}
+
+// SessionExpiresAt returns the time after which the provided Lease is expired,
+// or nil if it has been released at the end of the session or if it has never been renewed.
+func SessionExpiresAt(lease *coordinationv1.Lease) *metav1.Time {
+	if lease.Spec.HolderIdentity == nil {
+		return nil
+	}
+	renewTime := lease.Spec.RenewTime
+	if renewTime == nil {
+		renewTime = lease.Spec.AcquireTime
+	}
+	if renewTime == nil {
+		return nil
+	}
+	duration := SESSION_LEASE_DURATION
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return &metav1.Time{Time: renewTime.Add(duration)}
+}
+
+// MissingSessionExpiresAt returns the time after which the shell session on a resource duplicated at the provided
+// time is abandoned, if its Lease doesn't exist. The Lease is created right after the duplicated resource,
+// so it's missing only for a moment, unless its creation failed.
+func MissingSessionExpiresAt(creationTimestamp metav1.Time) *metav1.Time {
+	return &metav1.Time{Time: creationTimestamp.Add(SESSION_LEASE_DURATION)}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"testing"
+	"time"
+)
+
+func Test_SessionExpiresAt(t *testing.T) {
+	renewTime := metav1.NewMicroTime(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC))
+	lease := &coordinationv1.Lease{Spec: coordinationv1.LeaseSpec{
+		HolderIdentity:       ptr.To("laptop"),
+		RenewTime:            &renewTime,
+		LeaseDurationSeconds: ptr.To[int32](30),
+	}}
+	expiresAt := SessionExpiresAt(lease)
+	assert.Equal(t, time.Date(2025, 6, 1, 10, 0, 30, 0, time.UTC), expiresAt.Time.UTC())
+	assert.Nil(t, SessionExpiresAt(&coordinationv1.Lease{}))
+	// released at the end of the session
+	lease.Spec.HolderIdentity = nil
+	assert.Nil(t, SessionExpiresAt(lease))
+
+	obj := DuplicatedObject{SessionExpiresAt: expiresAt}
+	assert.True(t, obj.Abandoned(time.Date(2025, 6, 1, 10, 0, 30, 0, time.UTC)))
+	assert.False(t, obj.Abandoned(time.Date(2025, 6, 1, 10, 0, 29, 0, time.UTC)))
+	assert.False(t, DuplicatedObject{}.Abandoned(time.Now()))
+}
+
+func Test_MissingSessionExpiresAt(t *testing.T) {
+	creationTimestamp := metav1.NewTime(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC))
+	expiresAt := MissingSessionExpiresAt(creationTimestamp)
+	assert.Equal(t, time.Date(2025, 6, 1, 10, 1, 0, 0, time.UTC), expiresAt.Time.UTC())
+}
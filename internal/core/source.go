@@ -0,0 +1,99 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SourceObject is the resource a duplicated resource was copied from.
+type SourceObject struct {
+	APIVersion      string    `json:"apiVersion"`
+	Kind            string    `json:"kind"`
+	Name            string    `json:"name"`
+	UID             types.UID `json:"uid,omitempty"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+}
+
+// NewSourceObject returns the source of a duplicated resource copied from the provided object.
+func NewSourceObject(gvk schema.GroupVersionKind, obj metav1.Object) SourceObject {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return SourceObject{
+		APIVersion:      apiVersion,
+		Kind:            kind,
+		Name:            obj.GetName(),
+		UID:             obj.GetUID(),
+		ResourceVersion: obj.GetResourceVersion(),
+	}
+}
+
+// ParseSourceObject returns the source recorded in the provided annotations, or nil if there is none.
+func ParseSourceObject(annotations map[string]string) *SourceObject {
+	kind, ok := annotations[ANNOTATION_SOURCE_KIND]
+	if !ok {
+		return nil
+	}
+	return &SourceObject{
+		APIVersion:      annotations[ANNOTATION_SOURCE_API_VERSION],
+		Kind:            kind,
+		Name:            annotations[ANNOTATION_SOURCE_NAME],
+		UID:             types.UID(annotations[ANNOTATION_SOURCE_UID]),
+		ResourceVersion: annotations[ANNOTATION_SOURCE_RESOURCE_VERSION],
+	}
+}
+
+// Annotations returns the annotations recording the source in the duplicated resource.
+func (s SourceObject) Annotations() map[string]string {
+	return map[string]string{
+		ANNOTATION_SOURCE_API_VERSION:      s.APIVersion,
+		ANNOTATION_SOURCE_KIND:             s.Kind,
+		ANNOTATION_SOURCE_NAME:             s.Name,
+		ANNOTATION_SOURCE_UID:              string(s.UID),
+		ANNOTATION_SOURCE_RESOURCE_VERSION: s.ResourceVersion,
+	}
+}
+
+// GroupVersionKind returns the group, version and kind of the source.
+func (s SourceObject) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(s.APIVersion, s.Kind)
+}
+
+func (s SourceObject) String() string {
+	return s.Kind + "/" + s.Name
+}
+
+// OptionsAnnotation returns the annotation recording the provided options in the duplicated resource.
+func OptionsAnnotation(opts DuplicateOpts) (string, error) {
+	data, err := json.Marshal(opts)
+	return string(data), err
+}
+
+// ParseOptions returns the options recorded in the provided annotations, or nil if there are none.
+func ParseOptions(annotations map[string]string) *DuplicateOpts {
+	value, ok := annotations[ANNOTATION_OPTIONS]
+	if !ok {
+		return nil
+	}
+	var opts DuplicateOpts
+	if err := json.Unmarshal([]byte(value), &opts); err != nil {
+		return nil
+	}
+	return &opts
+}
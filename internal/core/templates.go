@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+)
+
+// defaultPodTemplatePath is the conventional path of the Pod template in workload resources.
+var defaultPodTemplatePath = []string{"spec", "template"}
+
+// podTemplatePaths maps the kinds of the known workload resources to the path of their Pod template.
+var podTemplatePaths = map[schema.GroupKind][]string{
+	{Group: "", Kind: "ReplicationController"}:             defaultPodTemplatePath,
+	{Group: "", Kind: "PodTemplate"}:                       {"template"},
+	{Group: "apps", Kind: "Deployment"}:                    defaultPodTemplatePath,
+	{Group: "apps", Kind: "StatefulSet"}:                   defaultPodTemplatePath,
+	{Group: "apps", Kind: "DaemonSet"}:                     defaultPodTemplatePath,
+	{Group: "apps", Kind: "ReplicaSet"}:                    defaultPodTemplatePath,
+	{Group: "batch", Kind: "Job"}:                          defaultPodTemplatePath,
+	{Group: "batch", Kind: "CronJob"}:                      {"spec", "jobTemplate", "spec", "template"},
+	{Group: "argoproj.io", Kind: "Rollout"}:                defaultPodTemplatePath,
+	{Group: "apps.openshift.io", Kind: "DeploymentConfig"}: defaultPodTemplatePath,
+}
+
+// DedicatedCommands maps the resources that can't be duplicated as generic resources, since their duplicates
+// are of another kind, to the command duplicating them.
+var DedicatedCommands = map[schema.GroupResource]string{
+	{Group: "batch", Resource: "cronjobs"}:  "cronjob",
+	{Group: "apps", Resource: "daemonsets"}: "daemonset",
+}
+
+// FindPodTemplatePath returns the path of the Pod template of the provided object.
+//
+// The custom paths, expressed in dot notation (e.g. "spec.template"), take precedence over
+// the built-in ones. If none of them matches, the conventional "spec.template" path is tried.
+func FindPodTemplatePath(u *unstructured.Unstructured, customPaths []string) ([]string, error) {
+	candidates := make([][]string, 0, len(customPaths)+2)
+	for _, p := range customPaths {
+		candidates = append(candidates, strings.Split(strings.Trim(p, "."), "."))
+	}
+	if p, ok := podTemplatePaths[u.GroupVersionKind().GroupKind()]; ok {
+		candidates = append(candidates, p)
+	}
+	candidates = append(candidates, defaultPodTemplatePath)
+
+	for _, path := range candidates {
+		if isPodTemplate(u, path) {
+			return path, nil
+		}
+	}
+	return nil, fmt.Errorf("no pod template found in %s %s", u.GetKind(), u.GetName())
+}
+
+// isPodTemplate returns true if the field at the provided path looks like a Pod template.
+func isPodTemplate(u *unstructured.Unstructured, path []string) bool {
+	containersPath := append(append([]string{}, path...), "spec", "containers")
+	containers, found, err := unstructured.NestedSlice(u.Object, containersPath...)
+	return err == nil && found && len(containers) > 0
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func newPodTemplate() map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "nginx"},
+			},
+		},
+	}
+}
+
+func Test_FindPodTemplatePath_BuiltIn(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"spec": map[string]interface{}{
+			"jobTemplate": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": newPodTemplate(),
+				},
+			},
+		},
+	}}
+	path, err := FindPodTemplatePath(u, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"spec", "jobTemplate", "spec", "template"}, path)
+}
+
+func Test_FindPodTemplatePath_DefaultPath(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Custom",
+		"spec": map[string]interface{}{
+			"template": newPodTemplate(),
+		},
+	}}
+	path, err := FindPodTemplatePath(u, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"spec", "template"}, path)
+}
+
+func Test_FindPodTemplatePath_CustomPath(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Custom",
+		"spec": map[string]interface{}{
+			"worker": map[string]interface{}{
+				"podTemplate": newPodTemplate(),
+			},
+		},
+	}}
+	path, err := FindPodTemplatePath(u, []string{"spec.worker.podTemplate"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"spec", "worker", "podTemplate"}, path)
+}
+
+func Test_FindPodTemplatePath_NotFound(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data":       map[string]interface{}{},
+	}}
+	_, err := FindPodTemplatePath(u, nil)
+	assert.Error(t, err)
+}
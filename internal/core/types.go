@@ -18,10 +18,12 @@ package core
 
 import (
 	"context"
-	v1 "k8s.io/api/core/v1"
+	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"time"
 )
 
 type Duplicator interface {
@@ -35,24 +37,98 @@ type Client interface {
 		namespace string,
 	) ([]DuplicableObject, error)
 	ListDuplicated(ctx context.Context, namespace string) ([]DuplicatedObject, error)
-	Delete(ctx context.Context, obj DuplicatedObject) error
+	Delete(ctx context.Context, obj DuplicatedObject, opts DeleteOpts) error
+	// Get returns the resource of the provided kind with the provided name.
+	Get(
+		ctx context.Context,
+		gvk schema.GroupVersionKind,
+		namespace string,
+		name string,
+	) (*unstructured.Unstructured, error)
+	// CurrentUser returns the name of the user the client is authenticated as.
+	CurrentUser(ctx context.Context) (string, error)
+}
+
+// ContainerOverride overrides the command and the args of a single container.
+type ContainerOverride struct {
+	// Command overrides the command of the container. If set, the container args are replaced by Args.
+	Command []string `json:"command,omitempty"`
+	// Args overrides the args of the container.
+	Args []string `json:"args,omitempty"`
 }
 
 type DuplicateOpts struct {
 	// Command overrides the default command of each container.
-	Command []string
+	Command []string `json:"command,omitempty"`
 	// Args overrides the default args of each container.
-	Args []string
-	// ReadinessProbe overrides the readiness probe of each container.
-	ReadinessProbe *v1.Probe
-	// LivenessProbe overrides the liveness probe of each container.
-	LivenessProbe *v1.Probe
-	// StartupProbe overrides the startup probe of each container.
-	StartupProbe *v1.Probe
+	Args []string `json:"args,omitempty"`
+	// LivenessProbe is the policy of the liveness probes, by container.
+	LivenessProbe OverridePolicies `json:"livenessProbe,omitempty"`
+	// ReadinessProbe is the policy of the readiness probes, by container.
+	ReadinessProbe OverridePolicies `json:"readinessProbe,omitempty"`
+	// StartupProbe is the policy of the startup probes, by container.
+	StartupProbe OverridePolicies `json:"startupProbe,omitempty"`
+	// PostStartHook is the policy of the postStart lifecycle hooks, by container.
+	PostStartHook OverridePolicies `json:"postStartHook,omitempty"`
+	// PreStopHook is the policy of the preStop lifecycle hooks, by container.
+	PreStopHook OverridePolicies `json:"preStopHook,omitempty"`
+	// Name is the name of the duplicated resource. If empty, it's generated from NameTemplate.
+	Name string `json:"name,omitempty"`
+	// NameTemplate is the Go template of the name of the duplicated resource, see NameTemplateData.
+	// If empty, DEFAULT_NAME_TEMPLATE is used.
+	NameTemplate string `json:"nameTemplate,omitempty"`
 	// StartInteractiveShell indicates whether to start an interactive shell in the duplicated pod.
-	StartInteractiveShell bool
+	StartInteractiveShell bool `json:"startInteractiveShell,omitempty"`
+	// Containers are the names of the containers targeted by the overrides, including init containers.
+	// If empty, all containers except init containers are targeted. Init containers other than native sidecars
+	// don't get the shared command and args, which would prevent them from completing.
+	Containers []string `json:"containers,omitempty"`
+	// ContainerOverrides overrides the command and the args of specific containers, by name.
+	// The containers are targeted even if they are not listed in Containers, but only by their own overrides.
+	ContainerOverrides map[string]ContainerOverride `json:"containerOverrides,omitempty"`
+	// ShellContainer is the name of the container where the interactive shell is started. If empty and
+	// the pod has more than one container, the user is prompted to select one.
+	ShellContainer string `json:"shellContainer,omitempty"`
+	// ShellPath is the path of the shell started in the duplicated pod.
+	// If empty, the shell is detected according to ShellPreference.
+	ShellPath string `json:"shellPath,omitempty"`
+	// ShellPreference is the list of shells looked up in the duplicated pod, in order of preference.
+	ShellPreference []string `json:"shellPreference,omitempty"`
+	// WaitTimeout is the maximum time to wait for the duplicated pod to be created and to be running.
+	WaitTimeout time.Duration `json:"-"`
+	// DebugTools indicates whether to inject debug tools in the duplicated pod, so that images without
+	// a shell (e.g. distroless) can be kept idle and inspected.
+	DebugTools bool `json:"debugTools,omitempty"`
+	// DebugToolsImage is the image providing the debug tools. Its statically linked binaries in /bin
+	// are copied to the duplicated containers.
+	DebugToolsImage string `json:"debugToolsImage,omitempty"`
 	// PreserveInitContainers indicates whether to preserve init containers in the duplicated pod.
-	PreserveInitContainers bool
+	// Native sidecars (init containers with restart policy Always) are always preserved.
+	PreserveInitContainers bool `json:"preserveInitContainers,omitempty"`
+	// KeepInitContainers are the names of the init containers to preserve in the duplicated pod.
+	KeepInitContainers []string `json:"keepInitContainers,omitempty"`
+	// DropInitContainers are the names of the init containers to remove from the duplicated pod,
+	// including native sidecars. It takes precedence over PreserveInitContainers and KeepInitContainers.
+	DropInitContainers []string `json:"dropInitContainers,omitempty"`
+	// Creator is the user creating the duplicated resource, recorded in its annotations.
+	Creator string `json:"-"`
+	// Note is a free text describing why the duplicated resource is created, recorded in its annotations.
+	Note string `json:"-"`
+	// TTL is the time to live of the duplicated resource, recorded as its expiration time.
+	// If zero, the resource doesn't expire.
+	TTL time.Duration `json:"-"`
+	// Metadata is the policy for propagating the labels and the annotations of the original resource.
+	Metadata MetadataPolicy `json:"metadata,omitempty"`
+	// DryRun defines whether the duplicated resource is actually created. Dry runs don't start the interactive shell.
+	DryRun DryRunStrategy `json:"-"`
+	// Output is the format, yaml or json, the duplicated resource is printed in. If empty, it's not printed.
+	Output string `json:"-"`
+	// Diff indicates whether to print the differences between the source and the duplicated resource.
+	Diff bool `json:"-"`
+	// KeepLabels indicates whether to keep the original selector and Pod template labels.
+	// By default, they are replaced with labels owned by duplik8s, so that the duplicated Pods
+	// are not selected by the Services and PodDisruptionBudgets of the original resource.
+	KeepLabels bool `json:"keepLabels,omitempty"`
 }
 
 type DuplicatedObject struct {
@@ -60,6 +136,54 @@ type DuplicatedObject struct {
 	Namespace         string
 	ObjectKind        schema.ObjectKind
 	CreationTimestamp metav1.Time
+	Labels            map[string]string
+	// Status is a short description of the state of the object (e.g. the phase of Pods).
+	Status string
+	// Node is the node of the object, if it's a Pod scheduled on a node.
+	Node string
+	// Source is the resource the object was copied from, or nil if it's unknown.
+	Source *SourceObject
+	// Options are the options the object was duplicated with, or nil if they are unknown.
+	Options *DuplicateOpts
+	// Creator is the user who created the object, or an empty string if it's unknown.
+	Creator string
+	// Note describes why the object was created, or is an empty string if none was provided.
+	Note string
+	// ExpiresAt is the time after which the object can be deleted, or nil if it doesn't expire.
+	ExpiresAt *metav1.Time
+	// SessionExpiresAt is the time after which the shell session on the object is considered abandoned,
+	// or nil if the object has no session.
+	SessionExpiresAt *metav1.Time
+	// Object is the content of the object as returned by the API server, or nil if it's unknown.
+	Object map[string]any
+}
+
+// Expired returns true if the object has expired at the provided time.
+func (o DuplicatedObject) Expired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(o.ExpiresAt.Time)
+}
+
+// Abandoned returns true if the shell session on the object has stopped renewing its lease at the provided time.
+func (o DuplicatedObject) Abandoned(now time.Time) bool {
+	return o.SessionExpiresAt != nil && !now.Before(o.SessionExpiresAt.Time)
+}
+
+type DeleteOpts struct {
+	// PropagationPolicy is how the dependents of the object are deleted. If empty, they are deleted in background.
+	PropagationPolicy metav1.DeletionPropagation
+	// Wait indicates whether to wait until the object and its dependent Pods are gone.
+	Wait bool
+	// WaitTimeout is the maximum time to wait for the deletion.
+	WaitTimeout time.Duration
+}
+
+// FinalizersError is returned when the deletion of an object is blocked by its finalizers.
+type FinalizersError struct {
+	Finalizers []string
+}
+
+func (e *FinalizersError) Error() string {
+	return fmt.Sprintf("deletion blocked by finalizers [%s]", strings.Join(e.Finalizers, ", "))
 }
 
 type DuplicableObject struct {
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CronJobClient duplicates CronJobs by triggering their Job template as a one-off Job,
+// like `kubectl create job --from=cronjob/<name>` does.
+type CronJobClient struct {
+	JobClient
+}
+
+func NewCronJobClient(opts utils.KubeOptions, rerun bool) (*CronJobClient, error) {
+	jobClient, err := NewJobClient(opts, rerun)
+	if err != nil {
+		return nil, err
+	}
+	return &CronJobClient{
+		JobClient: *jobClient,
+	}, nil
+}
+
+func (c *CronJobClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts) error {
+	fmt.Printf("duplicating cronjob %s\n", obj.Name)
+
+	// fetch the CronJob
+	cronJob, err := c.clientset.BatchV1().CronJobs(obj.Namespace).Get(c.ctx, obj.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if cronJob.Labels[core.LABEL_DUPLICATED] == "true" {
+		return fmt.Errorf("cronjob %s is already duplicated", obj.Name)
+	}
+
+	// create a new Job from the CronJob template
+	duplicatedJob, newName, err := createWithAvailableName(
+		c.ctx,
+		obj,
+		opts,
+		core.MAX_NAME_LENGTH,
+		c.clientset.BatchV1().Jobs(obj.Namespace).Get,
+		func(newName string) (*batchv1.Job, error) {
+			annotations := duplicatedAnnotations(
+				cronJob.Spec.JobTemplate.Annotations,
+				core.NewSourceObject(batchv1.SchemeGroupVersion.WithKind("CronJob"), cronJob),
+				opts,
+			)
+			annotations["cronjob.kubernetes.io/instantiate"] = "manual"
+			return c.createJob(
+				cronJob.Namespace,
+				newName,
+				duplicatedLabels(cronJob.Spec.JobTemplate.Labels, opts),
+				annotations,
+				*cronJob.Spec.JobTemplate.Spec.DeepCopy(),
+				opts,
+			)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("cronjob %q duplicated in job %q\n", obj.Name, newName)
+	printDiff(cronJob, duplicatedJob, opts)
+
+	if opts.StartInteractiveShell {
+		return c.startInteractiveShell(duplicatedJob, opts)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	"testing"
+)
+
+func newSourceCronJob() *batchv1.CronJob {
+	job := newSourceJob()
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default", Labels: map[string]string{"team": "core"}},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"app": "report"},
+					Annotations: map[string]string{"owner": "core"},
+				},
+				Spec: batchv1.JobSpec{
+					ActiveDeadlineSeconds:   ptr.To[int64](60),
+					TTLSecondsAfterFinished: ptr.To[int32](10),
+					Template:                job.Spec.Template,
+				},
+			},
+		},
+	}
+}
+
+func Test_CronJobDuplicator(t *testing.T) {
+	testCases := []struct {
+		name             string
+		rerun            bool
+		expectedDeadline *int64
+	}{
+		{name: "idle", rerun: false, expectedDeadline: nil},
+		{name: "rerun", rerun: true, expectedDeadline: ptr.To[int64](60)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewClientset(newSourceCronJob())
+			cronJobClient := &CronJobClient{
+				JobClient: JobClient{clientset: client, ctx: context.Background(), rerun: tc.rerun},
+			}
+
+			err := cronJobClient.Duplicate(core.DuplicableObject{Name: "report", Namespace: "default"}, core.DuplicateOpts{})
+			assert.NoError(t, err)
+			duplicated, err := client.BatchV1().Jobs("default").Get(
+				context.Background(),
+				"report-duplik8ted",
+				metav1.GetOptions{},
+			)
+			assert.NoError(t, err)
+
+			// the Job is marked as manually instantiated, like the ones created by kubectl create job --from
+			assert.Equal(t, "manual", duplicated.Annotations["cronjob.kubernetes.io/instantiate"])
+			assert.Equal(t, "core", duplicated.Annotations["owner"])
+			// the labels come from the Job template, not from the CronJob
+			assert.Equal(t, "report", duplicated.Labels["app"])
+			assert.Equal(t, "true", duplicated.Labels[core.LABEL_DUPLICATED])
+			assert.NotContains(t, duplicated.Labels, "team")
+			// the labels bound to the Jobs of the CronJob are stripped
+			for _, l := range jobControllerLabels {
+				assert.NotContains(t, duplicated.Spec.Template.Labels, l)
+			}
+			assert.Equal(t, core.NewInstanceLabels("report-duplik8ted"), duplicated.Spec.Template.Labels)
+
+			// the duplicated Job is not deleted once finished, and only idle copies ignore the deadline
+			assert.Nil(t, duplicated.Spec.TTLSecondsAfterFinished)
+			assert.Equal(t, tc.expectedDeadline, duplicated.Spec.ActiveDeadlineSeconds)
+		})
+	}
+}
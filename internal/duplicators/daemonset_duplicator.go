@@ -0,0 +1,213 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DaemonSetClient duplicates DaemonSets by creating a single Pod from their template,
+// pinned to a specific node.
+type DaemonSetClient struct {
+	clientset kubernetes.Interface
+	config    *rest.Config
+	ctx       context.Context
+	// node is the name of the node where the duplicated Pod is scheduled.
+	// If empty, the node is selected interactively.
+	node string
+	// startShell opens an interactive shell in the duplicated Pod.
+	startShell shellStarter
+}
+
+func NewDaemonSetClient(opts utils.KubeOptions, node string) (*DaemonSetClient, error) {
+	config, err := utils.NewRestConfig(opts.Kubeconfig, opts.Kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	return &DaemonSetClient{
+		clientset:  clientset,
+		config:     config,
+		ctx:        ctx,
+		node:       node,
+		startShell: newShellStarter(ctx, clientset, config),
+	}, nil
+}
+
+func (c *DaemonSetClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts) error {
+	fmt.Printf("duplicating daemonset %s\n", obj.Name)
+
+	// fetch the DaemonSet
+	daemonSet, err := c.clientset.AppsV1().DaemonSets(obj.Namespace).Get(c.ctx, obj.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if daemonSet.Labels[core.LABEL_DUPLICATED] == "true" {
+		return fmt.Errorf("daemonset %s is already duplicated", obj.Name)
+	}
+
+	// select the node where the duplicated pod will run
+	node := c.node
+	if node == "" {
+		node, err = c.selectNode(daemonSet)
+		if err != nil {
+			return err
+		}
+	}
+
+	// create a new pod from the daemonset template. The pod only keeps the template labels explicitly allowed,
+	// otherwise the DaemonSet controller would adopt it.
+	duplicatedPod, newName, err := createWithAvailableName(
+		c.ctx,
+		obj,
+		opts,
+		core.MAX_NAME_LENGTH,
+		c.clientset.CoreV1().Pods(obj.Namespace).Get,
+		func(newName string) (*v1.Pod, error) {
+			labels := opts.Metadata.Allowed(daemonSet.Spec.Template.Labels)
+			labels[core.LABEL_DUPLICATED] = "true"
+			newPod := v1.Pod{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Pod",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      newName,
+					Namespace: daemonSet.Namespace,
+					Labels:    labels,
+					Annotations: duplicatedAnnotations(
+						daemonSet.Spec.Template.Annotations,
+						core.NewSourceObject(appsv1.SchemeGroupVersion.WithKind("DaemonSet"), daemonSet),
+						opts,
+					),
+				},
+				Spec: *daemonSet.Spec.Template.Spec.DeepCopy(),
+			}
+
+			// override the pod spec
+			configurator := clients.NewConfigurator(c.clientset, opts)
+			err := configurator.OverrideSpec(c.ctx, obj.Namespace, &newPod.Spec)
+			if err != nil {
+				return nil, err
+			}
+			limitPodLifetime(&newPod.Spec, opts)
+
+			// pin the pod to the selected node, bypassing the scheduler
+			newPod.Spec.NodeName = node
+			if released := releaseHostPorts(&newPod.Spec); len(released) > 0 {
+				fmt.Fprintf(
+					os.Stderr,
+					"warning: removed %s, since they would conflict with the pod of daemonset %q on node %q\n",
+					strings.Join(released, ", "),
+					obj.Name,
+					node,
+				)
+			}
+
+			// create the new pod
+			return c.clientset.CoreV1().Pods(daemonSet.Namespace).Create(c.ctx, &newPod, metav1.CreateOptions{})
+		},
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("daemonset %q duplicated in pod %q on node %q\n", obj.Name, newName, node)
+	printDiff(daemonSet, duplicatedPod, opts)
+
+	if opts.StartInteractiveShell {
+		return c.startShell(*duplicatedPod, duplicatedPod, opts)
+	}
+
+	return nil
+}
+
+// releaseHostPorts removes the host ports from the provided Pod spec, since the duplicated Pod runs on the same node
+// as the original one and the kubelet rejects Pods whose host ports are already in use. The host network is kept,
+// since node agents (e.g. CNI plugins, log shippers) usually depend on it, but the ports of host network Pods
+// are removed, because the API server turns them into host ports. It returns the description of the removed ports.
+func releaseHostPorts(podSpec *v1.PodSpec) []string {
+	var released []string
+	for _, containers := range [][]v1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			if podSpec.HostNetwork {
+				for _, port := range containers[i].Ports {
+					released = append(released, fmt.Sprintf("host port %d of container %q", port.ContainerPort, containers[i].Name))
+				}
+				containers[i].Ports = nil
+				continue
+			}
+			for j := range containers[i].Ports {
+				port := &containers[i].Ports[j]
+				if port.HostPort != 0 {
+					released = append(released, fmt.Sprintf("host port %d of container %q", port.HostPort, containers[i].Name))
+					port.HostPort = 0
+					port.HostIP = ""
+				}
+			}
+		}
+	}
+	return released
+}
+
+// selectNode prompts the user to select one of the nodes where the DaemonSet is running.
+// If the DaemonSet is not running on any node, all the nodes of the cluster are available for selection.
+func (c *DaemonSetClient) selectNode(daemonSet *appsv1.DaemonSet) (string, error) {
+	nodes := make([]string, 0)
+	pods, err := c.clientset.CoreV1().Pods(daemonSet.Namespace).List(c.ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(daemonSet.Spec.Selector),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" && !slices.Contains(nodes, pod.Spec.NodeName) {
+			nodes = append(nodes, pod.Spec.NodeName)
+		}
+	}
+
+	if len(nodes) == 0 {
+		nodeList, err := c.clientset.CoreV1().Nodes().List(c.ctx, metav1.ListOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, node := range nodeList.Items {
+			nodes = append(nodes, node.Name)
+		}
+	}
+	if len(nodes) == 0 {
+		return "", fmt.Errorf("no nodes available for daemonset %s", daemonSet.Name)
+	}
+
+	slices.Sort(nodes)
+	return utils.SelectString(nodes, fmt.Sprintf("Nodes [%s]", daemonSet.Name))
+}
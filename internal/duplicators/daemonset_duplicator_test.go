@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func newSourceDaemonSet(hostNetwork bool) *appsv1.DaemonSet {
+	template := newSourceTemplate()
+	template.Spec.HostNetwork = hostNetwork
+	template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+		{Name: "metrics", ContainerPort: 9100, HostPort: 9100, HostIP: "0.0.0.0"},
+		{Name: "http", ContainerPort: 8080},
+	}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: newSourceSelector(),
+			Template: template,
+		},
+	}
+}
+
+func Test_DaemonSetDuplicator(t *testing.T) {
+	testCases := []struct {
+		name          string
+		hostNetwork   bool
+		expected      []string
+		expectedPorts []corev1.ContainerPort
+	}{
+		{
+			name:        "host ports",
+			hostNetwork: false,
+			expected:    []string{`host port 9100 of container "api"`},
+			expectedPorts: []corev1.ContainerPort{
+				{Name: "metrics", ContainerPort: 9100},
+				{Name: "http", ContainerPort: 8080},
+			},
+		},
+		{
+			// the ports of host network Pods are host ports
+			name:          "host network",
+			hostNetwork:   true,
+			expected:      []string{`host port 9100 of container "api"`, `host port 8080 of container "api"`},
+			expectedPorts: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			daemonSet := newSourceDaemonSet(tc.hostNetwork)
+			assert.Equal(t, tc.expected, releaseHostPorts(daemonSet.Spec.Template.Spec.DeepCopy()))
+
+			client := fake.NewClientset(daemonSet)
+			daemonSetClient := &DaemonSetClient{clientset: client, ctx: context.Background(), node: "node-1"}
+			err := daemonSetClient.Duplicate(core.DuplicableObject{Name: "agent", Namespace: "default"}, core.DuplicateOpts{})
+			assert.NoError(t, err)
+			duplicated, err := client.CoreV1().Pods("default").Get(context.Background(), "agent-duplik8ted", metav1.GetOptions{})
+			assert.NoError(t, err)
+
+			// the Pod runs on the selected node, without conflicting with the Pod of the DaemonSet
+			assert.Equal(t, "node-1", duplicated.Spec.NodeName)
+			assert.Equal(t, tc.hostNetwork, duplicated.Spec.HostNetwork)
+			assert.Equal(t, tc.expectedPorts, duplicated.Spec.Containers[0].Ports)
+			// the Pod is not adopted by the DaemonSet
+			assert.Equal(t, map[string]string{core.LABEL_DUPLICATED: "true"}, duplicated.Labels)
+		})
+	}
+}
+
+func Test_ReleaseHostPorts_Nothing(t *testing.T) {
+	podSpec := newSourceTemplate().Spec
+	assert.Empty(t, releaseHostPorts(&podSpec))
+	assert.Equal(t, newSourceTemplate().Spec, podSpec)
+}
@@ -24,27 +24,41 @@ import (
 	"github.com/telemaco019/duplik8s/internal/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 type DeploymentClient struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	config    *rest.Config
 	ctx       context.Context
 }
 
 func NewDeploymentClient(opts utils.KubeOptions) (*DeploymentClient, error) {
-	clientset, err := utils.NewClientset(opts.Kubeconfig, opts.Kubecontext)
+	config, err := utils.NewRestConfig(opts.Kubeconfig, opts.Kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, err
 	}
 	return &DeploymentClient{
 		clientset: clientset,
+		dynamic:   dynamicClient,
+		config:    config,
 		ctx:       context.Background(),
 	}, nil
 }
 
 func (c *DeploymentClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts) error {
-	fmt.Printf("duplicating deployment %s\n", obj.Name)
+	fmt.Fprintf(progressOutput(opts), "duplicating deployment %s\n", obj.Name)
 
 	// fetch the Deployment
 	deploy, err := c.clientset.AppsV1().Deployments(obj.Namespace).Get(c.ctx, obj.Name, metav1.GetOptions{})
@@ -56,47 +70,68 @@ func (c *DeploymentClient) Duplicate(obj core.DuplicableObject, opts core.Duplic
 	}
 
 	// create a new Deployment and override the spec
-	newName := fmt.Sprintf("%s-duplik8ted", deploy.Name)
-	newDeploy := appsv1.Deployment{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Deployment",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      newName,
-			Namespace: deploy.Namespace,
-			Labels: map[string]string{
-				core.LABEL_DUPLICATED: "true",
-			},
-		},
-		Spec: deploy.Spec,
-	}
+	duplicatedDeploy, newName, err := createWithAvailableName(
+		c.ctx,
+		obj,
+		opts,
+		core.MAX_NAME_LENGTH,
+		c.clientset.AppsV1().Deployments(obj.Namespace).Get,
+		func(newName string) (*appsv1.Deployment, error) {
+			newDeploy := appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      newName,
+					Namespace: deploy.Namespace,
+					Labels:    duplicatedLabels(deploy.Labels, opts),
+					Annotations: duplicatedAnnotations(
+						deploy.Annotations,
+						core.NewSourceObject(appsv1.SchemeGroupVersion.WithKind("Deployment"), deploy),
+						opts,
+					),
+				},
+				Spec: *deploy.Spec.DeepCopy(),
+			}
 
-	// override the spec of the deployment's pod
-	configurator := clients.NewConfigurator(c.clientset, opts)
-	err = configurator.OverrideSpec(c.ctx, obj.Namespace, &newDeploy.Spec.Template.Spec)
-	if err != nil {
-		return err
-	}
+			// isolate the duplicated pods from the services and selectors of the original ones
+			if !opts.KeepLabels {
+				newDeploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: core.NewInstanceLabels(newName)}
+				newDeploy.Spec.Template.Labels = isolatedLabels(newDeploy.Spec.Template.Labels, newName, opts)
+			}
+			newDeploy.Spec.Template.Annotations = opts.Metadata.Filter(newDeploy.Spec.Template.Annotations)
+
+			// override the spec of the deployment's pod
+			configurator := clients.NewConfigurator(c.clientset, opts)
+			err := configurator.OverrideSpec(c.ctx, obj.Namespace, &newDeploy.Spec.Template.Spec)
+			if err != nil {
+				return nil, err
+			}
 
-	// create the new deployment
-	duplicatedDeploy, err := c.clientset.AppsV1().Deployments(obj.Namespace).Create(c.ctx, &newDeploy, metav1.CreateOptions{})
+			// create the new deployment
+			return createDuplicated(c.ctx, &newDeploy, opts, c.clientset.AppsV1().Deployments(obj.Namespace).Create)
+		},
+	)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("deployment %q duplicated in %q\n", obj.Name, newName)
+	fmt.Fprintf(progressOutput(opts), "deployment %q duplicated in %q%s\n", obj.Name, newName, dryRunSuffix(opts))
+	printDiff(deploy, duplicatedDeploy, opts)
 
-	if opts.StartInteractiveShell {
-		pod, err := GetOwnedPod(
+	if opts.StartInteractiveShell && opts.DryRun == core.DryRunNone {
+		pod, err := WaitForOwnedPod(
 			c.ctx,
 			c.clientset,
-			newDeploy.Namespace,
+			c.dynamic,
+			duplicatedDeploy,
 			duplicatedDeploy.Spec.Selector,
+			opts.WaitTimeout,
 		)
 		if err != nil {
 			return err
 		}
-		return StartInteractiveShell(c.ctx, c.clientset, pod, duplicatedDeploy)
+		return StartInteractiveShell(c.ctx, c.clientset, c.config, pod, duplicatedDeploy, opts)
 	}
 
 	return nil
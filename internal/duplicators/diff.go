@@ -0,0 +1,73 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// printDiff prints the diff between the source and the duplicated object if requested by the options.
+// The duplicated object has already been created, so failures are only reported as warnings.
+func printDiff(source, duplicated runtime.Object, opts core.DuplicateOpts) {
+	if !opts.Diff {
+		return
+	}
+	w := progressOutput(opts)
+	diff, err := diffObjects(source, duplicated)
+	if err != nil {
+		fmt.Fprintf(w, "warning: failed to compute the differences with the source: %v\n", err)
+		return
+	}
+	if diff == "" {
+		fmt.Fprintln(w, "no differences with the source")
+		return
+	}
+	fmt.Fprint(w, utils.ColorizeDiff(diff))
+}
+
+func diffObjects(source, duplicated runtime.Object) (string, error) {
+	sourceObj, err := toUnstructured(source)
+	if err != nil {
+		return "", err
+	}
+	duplicatedObj, err := toUnstructured(duplicated)
+	if err != nil {
+		return "", err
+	}
+	return core.Diff(sourceObj, duplicatedObj)
+}
+
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+	gvk, err := objectKind(obj)
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
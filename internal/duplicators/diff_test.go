@@ -0,0 +1,48 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func Test_DiffObjects_DaemonSet(t *testing.T) {
+	container := corev1.Container{Name: "agent", Image: "agent", Command: []string{"agent"}}
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent"},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{container}},
+			},
+		},
+	}
+	container.Command = []string{"sleep", "infinity"}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-duplik8ted"},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{container}},
+	}
+
+	diff, err := diffObjects(daemonSet, pod)
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "--- DaemonSet/agent (source)\n+++ Pod/agent-duplik8ted (duplicate)\n")
+	assert.Contains(t, diff, "   - command:\n-    - agent\n+    - sleep\n+    - infinity\n")
+	assert.Contains(t, diff, "+  nodeName: node-1\n")
+}
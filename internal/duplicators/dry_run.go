@@ -0,0 +1,117 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"io"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"os"
+	"sigs.k8s.io/yaml"
+)
+
+// createDuplicated creates the provided duplicated object with the provided function, according to
+// the dry run strategy of the options, and prints the result in the output format of the options, if any.
+// With DryRunClient the object is returned as is, without calling the API server.
+func createDuplicated[T runtime.Object](
+	ctx context.Context,
+	obj T,
+	opts core.DuplicateOpts,
+	create func(context.Context, T, metav1.CreateOptions) (T, error),
+) (T, error) {
+	created := obj
+	if opts.DryRun != core.DryRunClient {
+		createOpts := metav1.CreateOptions{}
+		if opts.DryRun == core.DryRunServer {
+			createOpts.DryRun = []string{metav1.DryRunAll}
+		}
+		var err error
+		created, err = create(ctx, obj, createOpts)
+		if err != nil {
+			return created, err
+		}
+	}
+	if opts.Output != "" {
+		return created, printObject(os.Stdout, created, opts.Output)
+	}
+	return created, nil
+}
+
+// printObject prints the provided object in the provided format, yaml or json, without its managed fields.
+func printObject(w io.Writer, obj runtime.Object, output string) error {
+	obj = obj.DeepCopyObject()
+	gvk, err := objectKind(obj)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+
+	var data []byte
+	switch output {
+	case "yaml":
+		data, err = yaml.Marshal(obj)
+	case "json":
+		data, err = json.MarshalIndent(obj, "", "    ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unsupported output format %q: must be one of yaml or json", output)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// objectKind returns the kind of the provided object. The typed clients drop the kind of the objects they return,
+// so the kind of typed objects is looked up in the client-go scheme.
+func objectKind(obj runtime.Object) (schema.GroupVersionKind, error) {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		return gvk, nil
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return gvks[0], nil
+}
+
+// dryRunSuffix returns the suffix of the messages printed during a dry run.
+func dryRunSuffix(opts core.DuplicateOpts) string {
+	if opts.DryRun == core.DryRunNone {
+		return ""
+	}
+	return fmt.Sprintf(" (%s dry run)", opts.DryRun)
+}
+
+// progressOutput returns where the progress messages are printed, so that they don't mix with the printed object.
+func progressOutput(opts core.DuplicateOpts) io.Writer {
+	if opts.Output != "" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
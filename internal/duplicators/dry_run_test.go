@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func Test_CreateDuplicated(t *testing.T) {
+	testCases := []struct {
+		name            string
+		dryRun          core.DryRunStrategy
+		expectedCreated bool
+		expectedDryRun  []string
+	}{
+		{name: "no dry run", dryRun: core.DryRunNone, expectedCreated: true},
+		{name: "client", dryRun: core.DryRunClient, expectedCreated: false},
+		{name: "server", dryRun: core.DryRunServer, expectedCreated: true, expectedDryRun: []string{metav1.DryRunAll}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-duplik8ted", Namespace: "default"}}
+			var created bool
+			var createOpts metav1.CreateOptions
+			create := func(_ context.Context, pod *corev1.Pod, opts metav1.CreateOptions) (*corev1.Pod, error) {
+				created, createOpts = true, opts
+				return pod, nil
+			}
+			duplicated, err := createDuplicated(context.Background(), pod, core.DuplicateOpts{DryRun: tc.dryRun}, create)
+			assert.NoError(t, err)
+			assert.Equal(t, pod, duplicated)
+			assert.Equal(t, tc.expectedCreated, created)
+			assert.Equal(t, tc.expectedDryRun, createOpts.DryRun)
+		})
+	}
+}
+
+func Test_PrintObject(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "app-duplik8ted",
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "duplik8s"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, printObject(&buf, deploy, "yaml"))
+	assert.Contains(t, buf.String(), "apiVersion: apps/v1\nkind: Deployment\n")
+	assert.Contains(t, buf.String(), "name: app-duplik8ted")
+	assert.NotContains(t, buf.String(), "managedFields")
+	// the printed object is a copy
+	assert.Len(t, deploy.ManagedFields, 1)
+	assert.Empty(t, deploy.Kind)
+
+	buf.Reset()
+	assert.NoError(t, printObject(&buf, deploy, "json"))
+	assert.Contains(t, buf.String(), `"apiVersion": "apps/v1"`)
+
+	assert.EqualError(t, printObject(&buf, deploy, "wide"), `unsupported output format "wide": must be one of yaml or json`)
+}
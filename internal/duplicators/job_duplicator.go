@@ -0,0 +1,195 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+// jobControllerLabels are the labels added by the Job controller to the Pod template.
+// They are bound to the UID of the original Job, so the API server rejects any copy that keeps them.
+var jobControllerLabels = []string{
+	"controller-uid",
+	"job-name",
+	batchv1.ControllerUidLabel,
+	batchv1.JobNameLabel,
+}
+
+type JobClient struct {
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	config    *rest.Config
+	ctx       context.Context
+	// rerun indicates whether the duplicated Job should run the original command
+	// instead of being kept idle.
+	rerun bool
+}
+
+func NewJobClient(opts utils.KubeOptions, rerun bool) (*JobClient, error) {
+	config, err := utils.NewRestConfig(opts.Kubeconfig, opts.Kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &JobClient{
+		clientset: clientset,
+		dynamic:   dynamicClient,
+		config:    config,
+		ctx:       context.Background(),
+		rerun:     rerun,
+	}, nil
+}
+
+func (c *JobClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts) error {
+	fmt.Printf("duplicating job %s\n", obj.Name)
+
+	// fetch the Job
+	job, err := c.clientset.BatchV1().Jobs(obj.Namespace).Get(c.ctx, obj.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if job.Labels[core.LABEL_DUPLICATED] == "true" {
+		return fmt.Errorf("job %s is already duplicated", obj.Name)
+	}
+
+	// create the new job
+	duplicatedJob, newName, err := createWithAvailableName(
+		c.ctx,
+		obj,
+		opts,
+		core.MAX_NAME_LENGTH,
+		c.clientset.BatchV1().Jobs(obj.Namespace).Get,
+		func(newName string) (*batchv1.Job, error) {
+			return c.createJob(
+				job.Namespace,
+				newName,
+				duplicatedLabels(job.Labels, opts),
+				duplicatedAnnotations(
+					job.Annotations,
+					core.NewSourceObject(batchv1.SchemeGroupVersion.WithKind("Job"), job),
+					opts,
+				),
+				*job.Spec.DeepCopy(),
+				opts,
+			)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("job %q duplicated in %q\n", obj.Name, newName)
+	printDiff(job, duplicatedJob, opts)
+
+	if opts.StartInteractiveShell {
+		return c.startInteractiveShell(duplicatedJob, opts)
+	}
+
+	return nil
+}
+
+// createJob creates a new duplicated Job with the provided metadata and spec, overriding its Pod template
+// according to the provided options.
+func (c *JobClient) createJob(
+	namespace string,
+	name string,
+	labels map[string]string,
+	annotations map[string]string,
+	spec batchv1.JobSpec,
+	opts core.DuplicateOpts,
+) (*batchv1.Job, error) {
+	// let the API server generate a new selector for the duplicated Job
+	spec.Selector = nil
+	spec.ManualSelector = nil
+	// the lifetime of the duplicated Job is managed by duplik8s, so it's not deleted once finished
+	spec.TTLSecondsAfterFinished = nil
+	if !opts.KeepLabels {
+		// isolate the duplicated pods from the services of the original ones
+		spec.Template.Labels = isolatedLabels(spec.Template.Labels, name, opts)
+	}
+	for _, l := range jobControllerLabels {
+		delete(spec.Template.Labels, l)
+	}
+	spec.Template.Annotations = opts.Metadata.Filter(spec.Template.Annotations)
+
+	if c.rerun {
+		// keep the original command to reproduce the run
+		opts.Command = nil
+		opts.Args = nil
+	} else {
+		// an idle copy only needs a single Pod, which must not be killed by the deadline of the original run
+		// nor left failed by its backoff limit
+		spec.Parallelism = ptr.To[int32](1)
+		spec.Completions = ptr.To[int32](1)
+		spec.ActiveDeadlineSeconds = nil
+		spec.BackoffLimit = nil
+	}
+
+	newJob := batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: spec,
+	}
+
+	// override the spec of the job's pod
+	configurator := clients.NewConfigurator(c.clientset, opts)
+	err := configurator.OverrideSpec(c.ctx, namespace, &newJob.Spec.Template.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.clientset.BatchV1().Jobs(namespace).Create(c.ctx, &newJob, metav1.CreateOptions{})
+}
+
+func (c *JobClient) startInteractiveShell(duplicatedJob *batchv1.Job, opts core.DuplicateOpts) error {
+	pod, err := WaitForOwnedPod(
+		c.ctx,
+		c.clientset,
+		c.dynamic,
+		duplicatedJob,
+		duplicatedJob.Spec.Selector,
+		opts.WaitTimeout,
+	)
+	if err != nil {
+		return err
+	}
+	return StartInteractiveShell(c.ctx, c.clientset, c.config, pod, duplicatedJob, opts)
+}
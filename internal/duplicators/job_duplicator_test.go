@@ -0,0 +1,136 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	"testing"
+)
+
+func Test_JobDuplicator_Isolation(t *testing.T) {
+	client := fake.NewClientset(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: batchv1.JobSpec{
+			Selector:       newSourceSelector(),
+			ManualSelector: ptr.To(true),
+			Template:       newSourceTemplate(),
+		},
+	})
+	jobClient := &JobClient{clientset: client, ctx: context.Background()}
+
+	err := jobClient.Duplicate(core.DuplicableObject{Name: "api", Namespace: "default"}, core.DuplicateOpts{})
+	assert.NoError(t, err)
+	duplicated, err := client.BatchV1().Jobs("default").Get(context.Background(), "api-duplik8ted", metav1.GetOptions{})
+	assert.NoError(t, err)
+	// the API server generates a new selector for the duplicated Job
+	assert.Nil(t, duplicated.Spec.Selector)
+	assert.Nil(t, duplicated.Spec.ManualSelector)
+	assert.Equal(t, core.NewInstanceLabels("api-duplik8ted"), duplicated.Spec.Template.Labels)
+}
+
+// newSourceJob returns a Job whose Pod template has the labels added by the Job controller.
+func newSourceJob() *batchv1.Job {
+	template := newSourceTemplate()
+	template.Labels["controller-uid"] = "job-uid"
+	template.Labels["job-name"] = "api"
+	template.Labels[batchv1.ControllerUidLabel] = "job-uid"
+	template.Labels[batchv1.JobNameLabel] = "api"
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: "job-uid"},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{batchv1.ControllerUidLabel: "job-uid"},
+			},
+			Parallelism:             ptr.To[int32](3),
+			Completions:             ptr.To[int32](3),
+			ActiveDeadlineSeconds:   ptr.To[int64](60),
+			BackoffLimit:            ptr.To[int32](0),
+			TTLSecondsAfterFinished: ptr.To[int32](10),
+			Template:                template,
+		},
+	}
+}
+
+func Test_JobDuplicator_ControllerLabels(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts core.DuplicateOpts
+	}{
+		{name: "isolated", opts: core.DuplicateOpts{}},
+		{name: "keep labels", opts: core.DuplicateOpts{KeepLabels: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewClientset(newSourceJob())
+			jobClient := &JobClient{clientset: client, ctx: context.Background()}
+
+			err := jobClient.Duplicate(core.DuplicableObject{Name: "api", Namespace: "default"}, tc.opts)
+			assert.NoError(t, err)
+			duplicated, err := client.BatchV1().Jobs("default").Get(
+				context.Background(),
+				"api-duplik8ted",
+				metav1.GetOptions{},
+			)
+			assert.NoError(t, err)
+			// the labels bound to the original Job are rejected by the API server
+			for _, l := range jobControllerLabels {
+				assert.NotContains(t, duplicated.Spec.Template.Labels, l)
+			}
+			assert.Nil(t, duplicated.Spec.Selector)
+		})
+	}
+}
+
+func Test_JobDuplicator_Idle(t *testing.T) {
+	client := fake.NewClientset(newSourceJob())
+	jobClient := &JobClient{clientset: client, ctx: context.Background()}
+
+	err := jobClient.Duplicate(core.DuplicableObject{Name: "api", Namespace: "default"}, core.DuplicateOpts{})
+	assert.NoError(t, err)
+	duplicated, err := client.BatchV1().Jobs("default").Get(context.Background(), "api-duplik8ted", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, ptr.To[int32](1), duplicated.Spec.Parallelism)
+	assert.Equal(t, ptr.To[int32](1), duplicated.Spec.Completions)
+	assert.Nil(t, duplicated.Spec.ActiveDeadlineSeconds)
+	assert.Nil(t, duplicated.Spec.BackoffLimit)
+}
+
+func Test_JobDuplicator_Rerun(t *testing.T) {
+	client := fake.NewClientset(newSourceJob())
+	jobClient := &JobClient{clientset: client, ctx: context.Background(), rerun: true}
+
+	err := jobClient.Duplicate(
+		core.DuplicableObject{Name: "api", Namespace: "default"},
+		core.DuplicateOpts{Command: []string{"sleep", "infinity"}},
+	)
+	assert.NoError(t, err)
+	duplicated, err := client.BatchV1().Jobs("default").Get(context.Background(), "api-duplik8ted", metav1.GetOptions{})
+	assert.NoError(t, err)
+	// the run is reproduced as is
+	assert.Equal(t, ptr.To[int32](3), duplicated.Spec.Parallelism)
+	assert.Equal(t, ptr.To[int64](60), duplicated.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, ptr.To[int32](0), duplicated.Spec.BackoffLimit)
+	// the lifetime of the duplicated Job is managed by duplik8s
+	assert.Nil(t, duplicated.Spec.TTLSecondsAfterFinished)
+	assert.Nil(t, duplicated.Spec.Template.Spec.Containers[0].Command)
+}
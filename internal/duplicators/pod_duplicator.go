@@ -25,26 +25,37 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 type PodClient struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
+	config    *rest.Config
 	ctx       context.Context
+	// startShell opens an interactive shell in the duplicated Pod.
+	startShell shellStarter
 }
 
 func NewPodClient(opts utils.KubeOptions) (*PodClient, error) {
-	clientset, err := utils.NewClientset(opts.Kubeconfig, opts.Kubecontext)
+	config, err := utils.NewRestConfig(opts.Kubeconfig, opts.Kubecontext)
 	if err != nil {
 		return nil, err
 	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
 	return &PodClient{
-		clientset: clientset,
-		ctx:       context.Background(),
+		clientset:  clientset,
+		config:     config,
+		ctx:        ctx,
+		startShell: newShellStarter(ctx, clientset, config),
 	}, nil
 }
 
 func (c *PodClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts) error {
-	fmt.Printf("duplicating pod %s\n", obj.Name)
+	fmt.Fprintf(progressOutput(opts), "duplicating pod %s\n", obj.Name)
 
 	// fetch the pod
 	pod, err := c.clientset.CoreV1().Pods(obj.Namespace).Get(c.ctx, obj.Name, metav1.GetOptions{})
@@ -55,39 +66,55 @@ func (c *PodClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts
 		return fmt.Errorf("pod %s is already duplicated", obj.Name)
 	}
 
-	// create a new pod and override the spec
-	newName := fmt.Sprintf("%s-duplik8ted", pod.Name)
-	newPod := v1.Pod{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Pod",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      newName,
-			Namespace: pod.Namespace,
-			Labels: map[string]string{
-				core.LABEL_DUPLICATED: "true",
-			},
-		},
-		Spec: pod.Spec,
-	}
+	// create a new pod and override the spec. The pod only keeps the labels explicitly allowed,
+	// otherwise it would be selected by the services and the controllers of the original one.
+	duplicatedPod, newName, err := createWithAvailableName(
+		c.ctx,
+		obj,
+		opts,
+		core.MAX_NAME_LENGTH,
+		c.clientset.CoreV1().Pods(obj.Namespace).Get,
+		func(newName string) (*v1.Pod, error) {
+			labels := opts.Metadata.Allowed(pod.Labels)
+			labels[core.LABEL_DUPLICATED] = "true"
+			newPod := v1.Pod{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Pod",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      newName,
+					Namespace: pod.Namespace,
+					Labels:    labels,
+					Annotations: duplicatedAnnotations(
+						pod.Annotations,
+						core.NewSourceObject(v1.SchemeGroupVersion.WithKind("Pod"), pod),
+						opts,
+					),
+				},
+				Spec: *pod.Spec.DeepCopy(),
+			}
 
-	// override the pod spec
-	configurator := clients.NewConfigurator(c.clientset, opts)
-	err = configurator.OverrideSpec(c.ctx, obj.Namespace, &newPod.Spec)
-	if err != nil {
-		return err
-	}
+			// override the pod spec
+			configurator := clients.NewConfigurator(c.clientset, opts)
+			err := configurator.OverrideSpec(c.ctx, obj.Namespace, &newPod.Spec)
+			if err != nil {
+				return nil, err
+			}
+			limitPodLifetime(&newPod.Spec, opts)
 
-	// create the new pod
-	duplicatedPod, err := c.clientset.CoreV1().Pods(pod.Namespace).Create(c.ctx, &newPod, metav1.CreateOptions{})
+			// create the new pod
+			return createDuplicated(c.ctx, &newPod, opts, c.clientset.CoreV1().Pods(pod.Namespace).Create)
+		},
+	)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("pod %q duplicated in %q\n", obj.Name, newName)
+	fmt.Fprintf(progressOutput(opts), "pod %q duplicated in %q%s\n", obj.Name, newName, dryRunSuffix(opts))
+	printDiff(pod, duplicatedPod, opts)
 
-	if opts.StartInteractiveShell {
-		return StartInteractiveShell(c.ctx, c.clientset, newPod, duplicatedPod)
+	if opts.StartInteractiveShell && opts.DryRun == core.DryRunNone {
+		return c.startShell(*duplicatedPod, duplicatedPod, opts)
 	}
 
 	return nil
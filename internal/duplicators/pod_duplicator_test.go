@@ -0,0 +1,150 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runningOnCreate makes the fake clientset assign a UID to the created Pods and mark them as running,
+// like the API server and the kubelet would.
+func runningOnCreate(client *fake.Clientset) {
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.UID = "duplicated-uid"
+		pod.Status.Phase = corev1.PodRunning
+		for _, c := range pod.Spec.Containers {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+				Name:  c.Name,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			})
+		}
+		return false, nil, nil
+	})
+}
+
+func Test_PodDuplicator_ShellInCreatedPod(t *testing.T) {
+	client := fake.NewClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: "source-uid"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+	})
+	runningOnCreate(client)
+
+	var shellPod corev1.Pod
+	podClient := &PodClient{
+		clientset: client,
+		ctx:       context.Background(),
+		startShell: func(pod corev1.Pod, _ runtime.Object, opts core.DuplicateOpts) error {
+			shellPod = pod
+			// the Pod must be found by the wait, which filters the Pods by UID
+			return utils.WaitUntilPodRunning(context.Background(), client, pod, "nginx", opts.WaitTimeout)
+		},
+	}
+	err := podClient.Duplicate(
+		core.DuplicableObject{Name: "nginx", Namespace: "default"},
+		core.DuplicateOpts{StartInteractiveShell: true, WaitTimeout: 5 * time.Second},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx-duplik8ted", shellPod.Name)
+	assert.Equal(t, "duplicated-uid", string(shellPod.UID))
+}
+
+func Test_PodDuplicator_TTL(t *testing.T) {
+	client := fake.NewClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+	})
+	podClient := &PodClient{clientset: client, ctx: context.Background()}
+
+	before := time.Now().Truncate(time.Second)
+	err := podClient.Duplicate(core.DuplicableObject{Name: "nginx", Namespace: "default"}, core.DuplicateOpts{TTL: time.Hour})
+	assert.NoError(t, err)
+	duplicated, err := client.CoreV1().Pods("default").Get(context.Background(), "nginx-duplik8ted", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// the duplicated Pod expires after the TTL, and is terminated by the kubelet at the latest by then
+	expiresAt := core.ParseExpiresAt(duplicated.Annotations)
+	if assert.NotNil(t, expiresAt) {
+		assert.WithinRange(t, expiresAt.Time, before.Add(time.Hour), time.Now().Add(time.Hour))
+	}
+	assert.Equal(t, int64(3600), *duplicated.Spec.ActiveDeadlineSeconds)
+}
+
+func Test_PodDuplicator_NameTakenConcurrently(t *testing.T) {
+	client := fake.NewClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+	})
+	// another duplicate takes the name between the lookup and the creation
+	creations := 0
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		creations++
+		if creations > 1 {
+			return false, nil, nil
+		}
+		concurrent := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-duplik8ted", Namespace: "default"}}
+		if err := client.Tracker().Add(concurrent); err != nil {
+			return true, nil, err
+		}
+		return true, nil, apierrors.NewAlreadyExists(corev1.Resource("pods"), concurrent.Name)
+	})
+	podClient := &PodClient{clientset: client, ctx: context.Background()}
+
+	err := podClient.Duplicate(
+		core.DuplicableObject{Name: "nginx", Namespace: "default"},
+		core.DuplicateOpts{DebugTools: true},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, creations)
+	pods, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, pods.Items, 3)
+	duplicated := pods.Items[slices.IndexFunc(pods.Items, func(pod corev1.Pod) bool {
+		return strings.HasPrefix(pod.Name, "nginx-duplik8ted-")
+	})]
+	// the retried Pod is built from the original spec
+	assert.Len(t, duplicated.Spec.Volumes, 1)
+	assert.Len(t, duplicated.Spec.Containers[0].VolumeMounts, 1)
+}
+
+func Test_PodDuplicator_ProvidedNameTaken(t *testing.T) {
+	client := fake.NewClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+	})
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewAlreadyExists(corev1.Resource("pods"), "debug")
+	})
+	podClient := &PodClient{clientset: client, ctx: context.Background()}
+
+	// names provided by the user are never changed
+	err := podClient.Duplicate(core.DuplicableObject{Name: "nginx", Namespace: "default"}, core.DuplicateOpts{Name: "debug"})
+	assert.True(t, apierrors.IsAlreadyExists(err))
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"io"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"os"
+	"sync"
+	"time"
+)
+
+// sessionRenewalFailuresWarning is the number of consecutive renewal failures after which the user is warned
+// that the session may be considered abandoned. It's reached before the Lease expires.
+const sessionRenewalFailuresWarning = 2
+
+// sessionLease is the Lease renewed while the shell session on a duplicated resource is alive.
+// If the CLI dies during the session, the Lease stops being renewed and the duplicated resource
+// is considered abandoned, so it can be deleted by `cleanup --abandoned` or by the janitor.
+type sessionLease struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	cancel    context.CancelFunc
+	done      sync.WaitGroup
+	// errOut is where the repeated renewal failures are reported.
+	errOut io.Writer
+	// failures is the number of consecutive renewal failures.
+	failures int
+}
+
+// startSessionLease creates the Lease referenced by the annotations of the duplicated resource,
+// owned by the resource, and renews it in background until stopped.
+// It returns nil if the duplicated resource doesn't reference any Lease.
+func startSessionLease(
+	ctx context.Context,
+	client kubernetes.Interface,
+	duplicated runtime.Object,
+) (*sessionLease, error) {
+	accessor, err := meta.Accessor(duplicated)
+	if err != nil {
+		return nil, err
+	}
+	name := accessor.GetAnnotations()[core.ANNOTATION_SESSION_LEASE]
+	if name == "" {
+		return nil, nil
+	}
+	ownerRef, err := ownerReference(duplicated)
+	if err != nil {
+		return nil, err
+	}
+
+	holder, err := os.Hostname()
+	if err != nil {
+		holder = "duplik8s"
+	}
+	now := metav1.NowMicro()
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       accessor.GetNamespace(),
+			Labels:          map[string]string{core.LABEL_SESSION: "true"},
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: ptr.To(int32(core.SESSION_LEASE_DURATION.Seconds())),
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+	_, err = client.CoordinationV1().Leases(lease.Namespace).Create(ctx, lease, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	renewCtx, cancel := context.WithCancel(ctx)
+	l := &sessionLease{
+		client:    client,
+		namespace: lease.Namespace,
+		name:      lease.Name,
+		cancel:    cancel,
+		errOut:    os.Stderr,
+	}
+	l.done.Add(1)
+	go func() {
+		defer l.done.Done()
+		ticker := time.NewTicker(core.SESSION_LEASE_RENEW_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				l.renewed(renewCtx, l.renew(renewCtx))
+			}
+		}
+	}()
+	return l, nil
+}
+
+func (l *sessionLease) renew(ctx context.Context) error {
+	lease, err := l.client.CoordinationV1().Leases(l.namespace).Get(ctx, l.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	now := metav1.NowMicro()
+	lease.Spec.RenewTime = &now
+	_, err = l.client.CoordinationV1().Leases(l.namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// renewed tracks the outcome of a renewal. A single failure is not reported, since it would mess up
+// the shell session and the renewal is retried at the next tick; repeated failures are reported once,
+// since the duplicated resource may be considered abandoned and deleted before the session ends.
+func (l *sessionLease) renewed(ctx context.Context, err error) {
+	if err == nil {
+		l.failures = 0
+		return
+	}
+	if ctx.Err() != nil {
+		// the session has ended while renewing
+		return
+	}
+	l.failures++
+	if l.failures == sessionRenewalFailuresWarning {
+		// the terminal is in raw mode during the session, so the carriage returns are explicit
+		fmt.Fprintf(
+			l.errOut,
+			"\r\nwarning: failed to renew the session lease %q %d times in a row, "+
+				"the duplicated resource may be considered abandoned: %v\r\n",
+			l.name,
+			l.failures,
+			err,
+		)
+	}
+}
+
+// stop stops renewing the Lease, which expires after its duration.
+func (l *sessionLease) stop() {
+	if l == nil {
+		return
+	}
+	l.cancel()
+	l.done.Wait()
+}
+
+// release stops renewing the Lease and releases it by clearing its holder, so the duplicated resource
+// is no longer considered abandoned. The Lease is kept until the duplicated resource is deleted,
+// since a missing Lease means that the session is abandoned.
+func (l *sessionLease) release(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.stop()
+	lease, err := l.client.CoordinationV1().Leases(l.namespace).Get(ctx, l.name, metav1.GetOptions{})
+	if err == nil {
+		lease.Spec.HolderIdentity = nil
+		_, err = l.client.CoordinationV1().Leases(l.namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to release the session lease %q: %w", l.name, err)
+	}
+	return nil
+}
+
+// ownerReference returns a reference to the provided object, so that its dependents are garbage collected with it.
+// The kind of typed objects, which is not returned by the API server, is looked up in the client scheme.
+func ownerReference(obj runtime.Object) (metav1.OwnerReference, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return metav1.OwnerReference{}, err
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return metav1.OwnerReference{}, err
+		}
+		gvk = gvks[0]
+	}
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       accessor.GetName(),
+		UID:        accessor.GetUID(),
+	}, nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func Test_SessionLease(t *testing.T) {
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "app-duplik8ted",
+		Namespace:   "default",
+		UID:         "deploy-uid",
+		Annotations: map[string]string{core.ANNOTATION_SESSION_LEASE: "duplik8s-session-abc"},
+	}}
+	client := fake.NewClientset()
+
+	lease, err := startSessionLease(context.Background(), client, deploy)
+	assert.NoError(t, err)
+	created, err := client.CoordinationV1().Leases("default").Get(
+		context.Background(),
+		"duplik8s-session-abc",
+		metav1.GetOptions{},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "app-duplik8ted", UID: "deploy-uid"},
+	}, created.OwnerReferences)
+	assert.Equal(t, map[string]string{core.LABEL_SESSION: "true"}, created.Labels)
+	assert.NotNil(t, core.SessionExpiresAt(created))
+
+	// the released lease is kept, so the session is over rather than abandoned
+	assert.NoError(t, lease.release(context.Background()))
+	released, err := client.CoordinationV1().Leases("default").Get(
+		context.Background(),
+		"duplik8s-session-abc",
+		metav1.GetOptions{},
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, core.SessionExpiresAt(released))
+}
+
+func Test_SessionLease_NoAnnotation(t *testing.T) {
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app-duplik8ted", Namespace: "default"}}
+	lease, err := startSessionLease(context.Background(), fake.NewClientset(), deploy)
+	assert.NoError(t, err)
+	assert.Nil(t, lease)
+	// a missing lease can be stopped and released
+	lease.stop()
+	assert.NoError(t, lease.release(context.Background()))
+}
+
+func Test_SessionLease_RenewalFailures(t *testing.T) {
+	var errOut bytes.Buffer
+	lease := &sessionLease{name: "duplik8s-session-abc", errOut: &errOut}
+	renewErr := errors.New("connection refused")
+
+	// a single failure is not reported
+	lease.renewed(context.Background(), renewErr)
+	lease.renewed(context.Background(), nil)
+	assert.Empty(t, errOut.String())
+
+	// repeated failures are reported once
+	for range 3 {
+		lease.renewed(context.Background(), renewErr)
+	}
+	assert.Equal(
+		t,
+		"\r\nwarning: failed to renew the session lease \"duplik8s-session-abc\" 2 times in a row, "+
+			"the duplicated resource may be considered abandoned: connection refused\r\n",
+		errOut.String(),
+	)
+
+	// failures caused by the end of the session are not reported
+	errOut.Reset()
+	lease.failures = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 3 {
+		lease.renewed(ctx, ctx.Err())
+	}
+	assert.Empty(t, errOut.String())
+}
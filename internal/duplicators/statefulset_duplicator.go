@@ -24,27 +24,41 @@ import (
 	"github.com/telemaco019/duplik8s/internal/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 type StatefulSetClient struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	config    *rest.Config
 	ctx       context.Context
 }
 
 func NewStatefulSetClient(opts utils.KubeOptions) (*StatefulSetClient, error) {
-	clientset, err := utils.NewClientset(opts.Kubeconfig, opts.Kubecontext)
+	config, err := utils.NewRestConfig(opts.Kubeconfig, opts.Kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, err
 	}
 	return &StatefulSetClient{
 		clientset: clientset,
+		dynamic:   dynamicClient,
+		config:    config,
 		ctx:       context.Background(),
 	}, nil
 }
 
 func (c *StatefulSetClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts) error {
-	fmt.Printf("duplicating statefulset %s\n", obj.Name)
+	fmt.Fprintf(progressOutput(opts), "duplicating statefulset %s\n", obj.Name)
 
 	// fetch the StatefulSet
 	statefulSet, err := c.clientset.AppsV1().StatefulSets(obj.Namespace).Get(c.ctx, obj.Name, metav1.GetOptions{})
@@ -56,47 +70,68 @@ func (c *StatefulSetClient) Duplicate(obj core.DuplicableObject, opts core.Dupli
 	}
 
 	// create a new StatefulSet and override the spec
-	newName := fmt.Sprintf("%s-duplik8ted", statefulSet.Name)
-	newStatefulSet := appsv1.StatefulSet{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "StatefulSet",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      newName,
-			Namespace: statefulSet.Namespace,
-			Labels: map[string]string{
-				core.LABEL_DUPLICATED: "true",
-			},
-		},
-		Spec: statefulSet.Spec,
-	}
+	duplicatedStatefulSet, newName, err := createWithAvailableName(
+		c.ctx,
+		obj,
+		opts,
+		core.MAX_STATEFULSET_NAME_LENGTH,
+		c.clientset.AppsV1().StatefulSets(obj.Namespace).Get,
+		func(newName string) (*appsv1.StatefulSet, error) {
+			newStatefulSet := appsv1.StatefulSet{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "StatefulSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      newName,
+					Namespace: statefulSet.Namespace,
+					Labels:    duplicatedLabels(statefulSet.Labels, opts),
+					Annotations: duplicatedAnnotations(
+						statefulSet.Annotations,
+						core.NewSourceObject(appsv1.SchemeGroupVersion.WithKind("StatefulSet"), statefulSet),
+						opts,
+					),
+				},
+				Spec: *statefulSet.Spec.DeepCopy(),
+			}
 
-	// override the spec of the statefulset's pod
-	configurator := clients.NewConfigurator(c.clientset, opts)
-	err = configurator.OverrideSpec(c.ctx, obj.Namespace, &newStatefulSet.Spec.Template.Spec)
-	if err != nil {
-		return err
-	}
+			// isolate the duplicated pods from the services and selectors of the original ones
+			if !opts.KeepLabels {
+				newStatefulSet.Spec.Selector = &metav1.LabelSelector{MatchLabels: core.NewInstanceLabels(newName)}
+				newStatefulSet.Spec.Template.Labels = isolatedLabels(newStatefulSet.Spec.Template.Labels, newName, opts)
+			}
+			newStatefulSet.Spec.Template.Annotations = opts.Metadata.Filter(newStatefulSet.Spec.Template.Annotations)
+
+			// override the spec of the statefulset's pod
+			configurator := clients.NewConfigurator(c.clientset, opts)
+			err := configurator.OverrideSpec(c.ctx, obj.Namespace, &newStatefulSet.Spec.Template.Spec)
+			if err != nil {
+				return nil, err
+			}
 
-	// create the new statefulset
-	duplicatedStatefulSet, err := c.clientset.AppsV1().StatefulSets(obj.Namespace).Create(c.ctx, &newStatefulSet, metav1.CreateOptions{})
+			// create the new statefulset
+			return createDuplicated(c.ctx, &newStatefulSet, opts, c.clientset.AppsV1().StatefulSets(obj.Namespace).Create)
+		},
+	)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("statefulset %q duplicated in %q\n", obj.Name, newName)
+	fmt.Fprintf(progressOutput(opts), "statefulset %q duplicated in %q%s\n", obj.Name, newName, dryRunSuffix(opts))
+	printDiff(statefulSet, duplicatedStatefulSet, opts)
 
-	if opts.StartInteractiveShell {
-		pod, err := GetOwnedPod(
+	if opts.StartInteractiveShell && opts.DryRun == core.DryRunNone {
+		pod, err := WaitForOwnedPod(
 			c.ctx,
 			c.clientset,
-			duplicatedStatefulSet.Namespace,
+			c.dynamic,
+			duplicatedStatefulSet,
 			duplicatedStatefulSet.Spec.Selector,
+			opts.WaitTimeout,
 		)
 		if err != nil {
 			return err
 		}
-		return StartInteractiveShell(c.ctx, c.clientset, pod, duplicatedStatefulSet)
+		return StartInteractiveShell(c.ctx, c.clientset, c.config, pod, duplicatedStatefulSet, opts)
 	}
 
 	return nil
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"fmt"
+	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// UnstructuredClient duplicates any resource that embeds a Pod template,
+// including custom resources such as Argo Rollouts or OpenShift DeploymentConfigs.
+type UnstructuredClient struct {
+	clientset kubernetes.Interface
+	config    *rest.Config
+	dynamic   dynamic.Interface
+	ctx       context.Context
+	resource  schema.GroupVersionResource
+	// templatePaths are the user-provided paths of the Pod template, in dot notation.
+	templatePaths []string
+}
+
+func NewUnstructuredClient(
+	opts utils.KubeOptions,
+	resource string,
+	templatePaths []string,
+) (*UnstructuredClient, error) {
+	config, err := utils.NewRestConfig(opts.Kubeconfig, opts.Kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// resolve the resource, supporting short names (e.g. "rs") and fully qualified names (e.g. "rollouts.argoproj.io")
+	mapper := restmapper.NewShortcutExpander(
+		restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		discoveryClient,
+		nil,
+	)
+	gvr, gr := schema.ParseResourceArg(resource)
+	if gvr == nil {
+		gvr = &schema.GroupVersionResource{Group: gr.Group, Resource: gr.Resource}
+	}
+	resolved, err := mapper.ResourceFor(*gvr)
+	if err != nil {
+		return nil, fmt.Errorf("unknown resource %q: %w", resource, err)
+	}
+
+	return &UnstructuredClient{
+		clientset:     clientset,
+		config:        config,
+		dynamic:       dynamicClient,
+		ctx:           context.Background(),
+		resource:      resolved,
+		templatePaths: templatePaths,
+	}, nil
+}
+
+// Resource returns the resource duplicated by the client.
+func (c *UnstructuredClient) Resource() schema.GroupVersionResource {
+	return c.resource
+}
+
+func (c *UnstructuredClient) Duplicate(obj core.DuplicableObject, opts core.DuplicateOpts) error {
+	fmt.Printf("duplicating %s %s\n", c.resource.Resource, obj.Name)
+
+	// fetch the object
+	u, err := c.dynamic.Resource(c.resource).Namespace(obj.Namespace).Get(c.ctx, obj.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if u.GetLabels()[core.LABEL_DUPLICATED] == "true" {
+		return fmt.Errorf("%s %s is already duplicated", c.resource.Resource, obj.Name)
+	}
+
+	// extract the Pod template
+	templatePath, err := core.FindPodTemplatePath(u, c.templatePaths)
+	if err != nil {
+		return err
+	}
+	templateObj, _, err := unstructured.NestedMap(u.Object, templatePath...)
+	if err != nil {
+		return err
+	}
+	var template v1.PodTemplateSpec
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(templateObj, &template)
+	if err != nil {
+		return fmt.Errorf("failed to parse pod template: %w", err)
+	}
+
+	// create a new object with the same content of the original one
+	var duplicatedTemplate v1.PodTemplateSpec
+	resourceClient := c.dynamic.Resource(c.resource).Namespace(obj.Namespace)
+	duplicatedObj, newName, err := createWithAvailableName(
+		c.ctx,
+		obj,
+		opts,
+		core.MAX_NAME_LENGTH,
+		func(ctx context.Context, name string, opts metav1.GetOptions) (*unstructured.Unstructured, error) {
+			return resourceClient.Get(ctx, name, opts)
+		},
+		func(newName string) (*unstructured.Unstructured, error) {
+			newObj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			for k, v := range u.Object {
+				if k == "metadata" || k == "status" {
+					continue
+				}
+				newObj.Object[k] = runtime.DeepCopyJSONValue(v)
+			}
+			newObj.SetName(newName)
+			newObj.SetNamespace(u.GetNamespace())
+			newObj.SetLabels(duplicatedLabels(u.GetLabels(), opts))
+			newObj.SetAnnotations(duplicatedAnnotations(u.GetAnnotations(), core.NewSourceObject(u.GroupVersionKind(), u), opts))
+			duplicatedTemplate = *template.DeepCopy()
+
+			// isolate the duplicated pods from the services and selectors of the original ones
+			if !opts.KeepLabels {
+				err := isolateSelector(newObj, &duplicatedTemplate, newName, opts)
+				if err != nil {
+					return nil, err
+				}
+			}
+			duplicatedTemplate.Annotations = opts.Metadata.Filter(duplicatedTemplate.Annotations)
+
+			// override the spec of the pod template
+			configurator := clients.NewConfigurator(c.clientset, opts)
+			err := configurator.OverrideSpec(c.ctx, obj.Namespace, &duplicatedTemplate.Spec)
+			if err != nil {
+				return nil, err
+			}
+			templateObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&duplicatedTemplate)
+			if err != nil {
+				return nil, err
+			}
+			err = unstructured.SetNestedMap(newObj.Object, templateObj, templatePath...)
+			if err != nil {
+				return nil, err
+			}
+
+			// create the new object
+			return c.dynamic.Resource(c.resource).Namespace(obj.Namespace).Create(c.ctx, newObj, metav1.CreateOptions{})
+		},
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %q duplicated in %q\n", c.resource.Resource, obj.Name, newName)
+	printDiff(u, duplicatedObj, opts)
+
+	if opts.StartInteractiveShell {
+		selector, err := podSelector(duplicatedObj, duplicatedTemplate)
+		if err != nil {
+			return err
+		}
+		pod, err := WaitForOwnedPod(c.ctx, c.clientset, c.dynamic, duplicatedObj, selector, opts.WaitTimeout)
+		if err != nil {
+			return err
+		}
+		return startInteractiveShell(c.ctx, c.clientset, c.config, pod, duplicatedObj, opts, func() error {
+			propagation := metav1.DeletePropagationBackground
+			return c.dynamic.Resource(c.resource).
+				Namespace(duplicatedObj.GetNamespace()).
+				Delete(c.ctx, duplicatedObj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+		})
+	}
+
+	return nil
+}
+
+// podSelector returns the selector of the Pods created by the provided object.
+// If the object has no selector, the labels of the Pod template are used.
+func podSelector(u *unstructured.Unstructured, template v1.PodTemplateSpec) (*metav1.LabelSelector, error) {
+	selector, err := clients.PodSelector(u)
+	if err != nil || selector != nil {
+		return selector, err
+	}
+	return &metav1.LabelSelector{MatchLabels: template.Labels}, nil
+}
+
+// isolateSelector replaces the selector of the provided object and the labels of its Pod template
+// with labels owned by duplik8s. Both label selectors and plain label maps are supported.
+// Label selectors are replaced as a whole, since their match expressions would still refer to the original labels.
+func isolateSelector(u *unstructured.Unstructured, template *v1.PodTemplateSpec, name string, opts core.DuplicateOpts) error {
+	labels := core.NewInstanceLabels(name)
+	template.Labels = isolatedLabels(template.Labels, name, opts)
+
+	selectorObj, found, err := unstructured.NestedMap(u.Object, "spec", "selector")
+	if err != nil || !found {
+		return err
+	}
+	_, hasMatchLabels := selectorObj["matchLabels"]
+	_, hasMatchExpressions := selectorObj["matchExpressions"]
+	if hasMatchLabels || hasMatchExpressions {
+		selector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&metav1.LabelSelector{MatchLabels: labels})
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedMap(u.Object, selector, "spec", "selector")
+	}
+	return unstructured.SetNestedStringMap(u.Object, labels, "spec", "selector")
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+var rolloutsResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+
+func newRollout(t *testing.T, name string) *unstructured.Unstructured {
+	selector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(newSourceSelector())
+	assert.NoError(t, err)
+	template := newSourceTemplate()
+	templateObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&template)
+	assert.NoError(t, err)
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"spec":       map[string]interface{}{"selector": selector, "template": templateObj},
+	}}
+}
+
+func Test_UnstructuredDuplicator_Isolation(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newRollout(t, "api"))
+	unstructuredClient := &UnstructuredClient{
+		clientset: fake.NewClientset(),
+		dynamic:   dynamicClient,
+		ctx:       context.Background(),
+		resource:  rolloutsResource,
+	}
+
+	err := unstructuredClient.Duplicate(core.DuplicableObject{Name: "api", Namespace: "default"}, core.DuplicateOpts{})
+	assert.NoError(t, err)
+	duplicated, err := dynamicClient.Resource(rolloutsResource).Namespace("default").Get(
+		context.Background(),
+		"api-duplik8ted",
+		metav1.GetOptions{},
+	)
+	assert.NoError(t, err)
+	// the match expressions of the original selector are removed
+	selector, _, err := unstructured.NestedMap(duplicated.Object, "spec", "selector")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"matchLabels": map[string]interface{}{core.LABEL_INSTANCE: "api-duplik8ted"},
+	}, selector)
+	labels, _, err := unstructured.NestedStringMap(duplicated.Object, "spec", "template", "metadata", "labels")
+	assert.NoError(t, err)
+	assert.Equal(t, core.NewInstanceLabels("api-duplik8ted"), labels)
+}
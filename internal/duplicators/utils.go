@@ -20,32 +20,92 @@ import (
 	"context"
 	"fmt"
 	"github.com/charmbracelet/huh"
+	"github.com/telemaco019/duplik8s/internal/clients"
+	"github.com/telemaco019/duplik8s/internal/core"
 	"github.com/telemaco019/duplik8s/internal/utils"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"maps"
+	"path"
+	"slices"
+	"strings"
 	"time"
 )
 
 func StartInteractiveShell(
 	ctx context.Context,
-	clientset *kubernetes.Clientset,
+	clientset kubernetes.Interface,
+	config *rest.Config,
 	pod corev1.Pod,
 	duplicatedObject runtime.Object,
+	opts core.DuplicateOpts,
 ) error {
-	// wait for the pod to be ready
-	fmt.Printf("waiting for the duplicated pod %q to be ready...\n", pod.Name)
-	err := utils.WaitUntilPodReady(ctx, clientset, pod, 60*time.Second)
+	return startInteractiveShell(ctx, clientset, config, pod, duplicatedObject, opts, func() error {
+		return deleteResource(ctx, clientset, duplicatedObject)
+	})
+}
+
+// shellStarter opens an interactive shell in the provided Pod of a duplicated resource.
+type shellStarter func(pod corev1.Pod, duplicatedObject runtime.Object, opts core.DuplicateOpts) error
+
+func newShellStarter(ctx context.Context, clientset kubernetes.Interface, config *rest.Config) shellStarter {
+	return func(pod corev1.Pod, duplicatedObject runtime.Object, opts core.DuplicateOpts) error {
+		return StartInteractiveShell(ctx, clientset, config, pod, duplicatedObject, opts)
+	}
+}
+
+// startInteractiveShell opens a shell in the provided Pod and, once the session is over,
+// asks the user whether to delete the duplicated resource with the provided function.
+// The session lease of the duplicated resource is renewed until the user answers.
+func startInteractiveShell(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	config *rest.Config,
+	pod corev1.Pod,
+	duplicated runtime.Object,
+	opts core.DuplicateOpts,
+	deleteDuplicated func() error,
+) error {
+	container, err := selectContainer(pod, opts.ShellContainer)
 	if err != nil {
 		return err
 	}
-	fmt.Println("Pod is ready, launching shell...")
-	execCmd := []string{
-		"kubectl", "exec", "-it", pod.Name, "-n", pod.Namespace, "--", "/bin/sh",
+
+	lease, err := startSessionLease(ctx, clientset, duplicated)
+	if err != nil {
+		fmt.Printf("warning: failed to create the session lease, the duplicated resource will be considered "+
+			"abandoned during the session and may be cleaned up: %v\n", err)
+	}
+	defer lease.stop()
+
+	// wait for the pod to be running
+	fmt.Printf("waiting for the duplicated pod %q to be running...\n", pod.Name)
+	err = utils.WaitUntilPodRunning(ctx, clientset, pod, container, opts.WaitTimeout)
+	if err != nil {
+		return err
 	}
-	if err = utils.RunInteractive(execCmd); err != nil {
+	shell := []string{opts.ShellPath}
+	if opts.ShellPath == "" {
+		shell, err = utils.DetectShell(ctx, config, clientset, pod, container, shellPreference(opts))
+		if err != nil {
+			return fmt.Errorf("%w: use --shell-path to provide the path of the shell", err)
+		}
+	}
+	fmt.Printf("Pod is running, launching %s in container %q...\n", strings.Join(shell, " "), container)
+	err = utils.ExecInteractive(ctx, config, clientset, pod, container, sessionShell(pod, container, shell, opts))
+	if err != nil {
 		return fmt.Errorf("error during shell session: %w", err)
 	}
 
@@ -57,9 +117,12 @@ func StartInteractiveShell(
 	if err != nil {
 		return err
 	}
+	err = lease.release(ctx)
+	if err != nil {
+		return err
+	}
 	if confirmDelete {
-		// Delete the duplicated object based on its type
-		err = deleteResource(ctx, clientset, duplicatedObject)
+		err = deleteDuplicated()
 		if err != nil {
 			return err
 		}
@@ -71,7 +134,174 @@ func StartInteractiveShell(
 	return nil
 }
 
-func deleteResource(ctx context.Context, clientset *kubernetes.Clientset, duplicatedObject runtime.Object) error {
+// selectContainer returns the container of the Pod where the shell is opened. If no container is provided
+// and the Pod has more than one container, the user is prompted to select one.
+func selectContainer(pod corev1.Pod, container string) (string, error) {
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	if container != "" {
+		// native sidecars are running as well, so the shell can be opened in them
+		sidecar := slices.ContainsFunc(pod.Spec.InitContainers, func(c corev1.Container) bool {
+			return c.Name == container && c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways
+		})
+		if !slices.Contains(names, container) && !sidecar {
+			return "", fmt.Errorf("container %q not found in pod %q", container, pod.Name)
+		}
+		return container, nil
+	}
+	if len(names) == 1 {
+		return names[0], nil
+	}
+	return utils.SelectString(names, fmt.Sprintf("Containers [%s]", pod.Name))
+}
+
+// nameConflictRetries is the number of times the creation of a duplicate is retried
+// when its name is taken by a concurrent duplicate.
+const nameConflictRetries = 3
+
+// newDuplicatedName returns the name of the duplicate of the provided object, no longer than maxLength.
+// The getter is used to check whether the name is already taken.
+func newDuplicatedName[T any](
+	ctx context.Context,
+	obj core.DuplicableObject,
+	opts core.DuplicateOpts,
+	maxLength int,
+	get func(ctx context.Context, name string, opts metav1.GetOptions) (T, error),
+) (string, error) {
+	return core.NewDuplicatedName(obj, opts, maxLength, func(name string) (bool, error) {
+		_, err := get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// createWithAvailableName creates the duplicate of the provided object with the create function, named after
+// the first available name. Since a concurrent duplicate may take the name between the lookup and the creation,
+// the lookup and the creation are retried, so that the duplicate gets a fresh suffix. Names provided by the user
+// are never changed.
+func createWithAvailableName[G, T any](
+	ctx context.Context,
+	obj core.DuplicableObject,
+	opts core.DuplicateOpts,
+	maxLength int,
+	get func(ctx context.Context, name string, opts metav1.GetOptions) (G, error),
+	create func(name string) (T, error),
+) (T, string, error) {
+	for i := 0; ; i++ {
+		name, err := newDuplicatedName(ctx, obj, opts, maxLength, get)
+		if err != nil {
+			var zero T
+			return zero, "", err
+		}
+		created, err := create(name)
+		if apierrors.IsAlreadyExists(err) && opts.Name == "" && i < nameConflictRetries {
+			continue
+		}
+		return created, name, err
+	}
+}
+
+// duplicatedLabels returns the labels of the duplicated resource: the original ones
+// filtered by the metadata policy, plus the label marking the resource as duplicated.
+func duplicatedLabels(labels map[string]string, opts core.DuplicateOpts) map[string]string {
+	duplicated := opts.Metadata.Filter(labels)
+	duplicated[core.LABEL_DUPLICATED] = "true"
+	return duplicated
+}
+
+// duplicatedAnnotations returns the annotations of the duplicated resource: the original ones
+// filtered by the metadata policy, plus the source, the options, the creator and the note of the duplicate,
+// its expiration time and the name of the lease of its shell session, if any.
+func duplicatedAnnotations(
+	annotations map[string]string,
+	source core.SourceObject,
+	opts core.DuplicateOpts,
+) map[string]string {
+	duplicated := opts.Metadata.Filter(annotations)
+	maps.Copy(duplicated, source.Annotations())
+	if options, err := core.OptionsAnnotation(opts); err == nil {
+		duplicated[core.ANNOTATION_OPTIONS] = options
+	}
+	if opts.Creator != "" {
+		duplicated[core.ANNOTATION_CREATOR] = opts.Creator
+	}
+	if opts.Note != "" {
+		duplicated[core.ANNOTATION_NOTE] = opts.Note
+	}
+	if opts.TTL > 0 {
+		duplicated[core.ANNOTATION_EXPIRES_AT] = time.Now().Add(opts.TTL).UTC().Format(time.RFC3339)
+	}
+	if opts.StartInteractiveShell {
+		duplicated[core.ANNOTATION_SESSION_LEASE] = core.NewSessionLeaseName()
+	}
+	return duplicated
+}
+
+// limitPodLifetime makes the kubelet terminate the duplicated Pod once its TTL has expired.
+func limitPodLifetime(podSpec *corev1.PodSpec, opts core.DuplicateOpts) {
+	if opts.TTL <= 0 {
+		return
+	}
+	seconds := max(int64(opts.TTL.Seconds()), 1)
+	if podSpec.ActiveDeadlineSeconds == nil || *podSpec.ActiveDeadlineSeconds > seconds {
+		podSpec.ActiveDeadlineSeconds = &seconds
+	}
+}
+
+// isolatedLabels returns the labels of the duplicated Pods, isolated from the selectors of the original ones:
+// the labels owned by duplik8s, plus the original ones explicitly allowed by the metadata policy.
+func isolatedLabels(labels map[string]string, name string, opts core.DuplicateOpts) map[string]string {
+	isolated := opts.Metadata.Allowed(labels)
+	maps.Copy(isolated, core.NewInstanceLabels(name))
+	return isolated
+}
+
+// shellPreference returns the shells looked up in the duplicated pod, in order of preference.
+// The shells of the image take precedence over the ones provided by the debug tools.
+func shellPreference(opts core.DuplicateOpts) []string {
+	if !opts.DebugTools {
+		return opts.ShellPreference
+	}
+	preference := slices.Clone(opts.ShellPreference)
+	for _, shell := range opts.ShellPreference {
+		if !strings.Contains(shell, "/") {
+			preference = append(preference, path.Join(core.DEBUG_TOOLS_DIR, shell))
+		}
+	}
+	return preference
+}
+
+// sessionShell returns the command starting the provided shell in a container of the duplicated pod.
+// With the debug tools, the tools are appended to the PATH of the session, unless the container already has them
+// in its PATH (i.e. it's idle), so that the PATH of the image is preserved.
+func sessionShell(pod corev1.Pod, container string, shell []string, opts core.DuplicateOpts) []string {
+	if !opts.DebugTools || hasDebugToolsPath(pod, container) {
+		return shell
+	}
+	script := fmt.Sprintf(`PATH="${PATH:+$PATH:}%s"; export PATH; exec %s`, core.DEBUG_TOOLS_DIR, strings.Join(shell, " "))
+	return append(slices.Clone(shell), "-c", script)
+}
+
+// hasDebugToolsPath returns true if the debug tools are in the PATH set on a container of the provided pod.
+func hasDebugToolsPath(pod corev1.Pod, container string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name != container {
+			continue
+		}
+		for _, env := range c.Env {
+			if env.Name == "PATH" && slices.Contains(strings.Split(env.Value, ":"), core.DEBUG_TOOLS_DIR) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func deleteResource(ctx context.Context, clientset kubernetes.Interface, duplicatedObject runtime.Object) error {
 	switch obj := duplicatedObject.(type) {
 	case *corev1.Pod:
 		return clientset.CoreV1().Pods(obj.Namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
@@ -79,29 +309,69 @@ func deleteResource(ctx context.Context, clientset *kubernetes.Clientset, duplic
 		return clientset.AppsV1().Deployments(obj.Namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
 	case *appsv1.StatefulSet:
 		return clientset.AppsV1().StatefulSets(obj.Namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
+	case *batchv1.Job:
+		// Jobs orphan their Pods by default, delete them in background
+		propagation := metav1.DeletePropagationBackground
+		return clientset.BatchV1().Jobs(obj.Namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+		})
 	default:
 		return fmt.Errorf("unsupported duplicated object type: %T", obj)
 	}
 }
 
-func GetOwnedPod(
+// WaitForOwnedPod waits until a Pod owned by the provided object is created, and returns it.
+// A Pod is owned by the object if the object is its controller, either directly (e.g. StatefulSets, Jobs)
+// or through an intermediate controller of any kind (e.g. the ReplicaSets of Deployments and Argo Rollouts,
+// or the ReplicationControllers of OpenShift DeploymentConfigs). The optional selector narrows the Pods to watch.
+func WaitForOwnedPod(
 	ctx context.Context,
-	client *kubernetes.Clientset,
-	namespace string,
+	client kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	owner metav1.Object,
 	selector *metav1.LabelSelector,
+	timeout time.Duration,
 ) (corev1.Pod, error) {
-	labelSelector := metav1.FormatLabelSelector(selector)
+	watchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	podList, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: labelSelector,
-	})
-	if err != nil {
-		return corev1.Pod{}, fmt.Errorf("failed to list pods with selector %q: %w", labelSelector, err)
+	listOptions := func(options metav1.ListOptions) metav1.ListOptions {
+		if selector != nil {
+			options.LabelSelector = metav1.FormatLabelSelector(selector)
+		}
+		return options
 	}
-
-	if len(podList.Items) == 0 {
-		return corev1.Pod{}, fmt.Errorf("no pods found with selector %q", labelSelector)
+	lw := &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Pods(owner.GetNamespace()).List(ctx, listOptions(options))
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Pods(owner.GetNamespace()).Watch(ctx, listOptions(options))
+		},
 	}
 
-	return podList.Items[0], nil
+	var ownedPod *corev1.Pod
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client.Discovery()))
+	resolver := clients.NewControllerResolver(dynamicClient, mapper)
+	_, err := watchtools.UntilWithSync(watchCtx, lw, &corev1.Pod{}, nil, func(event watch.Event) (bool, error) {
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok || event.Type == watch.Deleted || pod.DeletionTimestamp != nil {
+			return false, nil
+		}
+		owned, err := resolver.IsOwnedBy(watchCtx, pod, owner.GetUID())
+		if err != nil {
+			return false, err
+		}
+		if owned {
+			ownedPod = pod
+		}
+		return owned, nil
+	})
+	if err != nil {
+		if watchCtx.Err() != nil {
+			return corev1.Pod{}, fmt.Errorf("no pod owned by %q created within %s", owner.GetName(), timeout)
+		}
+		return corev1.Pod{}, fmt.Errorf("failed to wait for pods owned by %q: %w", owner.GetName(), err)
+	}
+	return *ownedPod, nil
 }
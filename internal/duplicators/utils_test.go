@@ -0,0 +1,316 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package duplicators
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"testing"
+	"time"
+)
+
+func newControllerRef(apiVersion, kind, name string, uid types.UID) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid, Controller: ptr.To(true)},
+	}
+}
+
+// newOwnerClients returns a clientset and a dynamic client serving the provided objects,
+// whose discovery knows the intermediate controllers of the Pods.
+func newOwnerClients(objs ...runtime.Object) (*fake.Clientset, *dynamicfake.FakeDynamicClient) {
+	client := fake.NewClientset(objs...)
+	client.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "replicasets", Namespaced: true, Kind: "ReplicaSet"}},
+		},
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "replicationcontrollers", Namespaced: true, Kind: "ReplicationController"},
+			},
+		},
+	}
+	return client, dynamicfake.NewSimpleDynamicClient(scheme.Scheme, objs...)
+}
+
+func Test_WaitForOwnedPod_ThroughReplicaSet(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-duplik8ted", Namespace: "default", UID: "deploy-uid"},
+	}
+	client, dynamicClient := newOwnerClients(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-duplik8ted-abc",
+			Namespace:       "default",
+			UID:             "rs-uid",
+			OwnerReferences: newControllerRef("apps/v1", "Deployment", deploy.Name, deploy.UID),
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-def",
+			Namespace:       "default",
+			UID:             "original-rs-uid",
+			OwnerReferences: newControllerRef("apps/v1", "Deployment", "app", "original-uid"),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-def-1",
+			Namespace:       "default",
+			OwnerReferences: newControllerRef("apps/v1", "ReplicaSet", "app-def", "original-rs-uid"),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-duplik8ted-abc-1",
+			Namespace:       "default",
+			OwnerReferences: newControllerRef("apps/v1", "ReplicaSet", "app-duplik8ted-abc", "rs-uid"),
+		}},
+	)
+
+	pod, err := WaitForOwnedPod(context.Background(), client, dynamicClient, deploy, nil, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-duplik8ted-abc-1", pod.Name)
+}
+
+func Test_WaitForOwnedPod_ThroughReplicationController(t *testing.T) {
+	// e.g. OpenShift DeploymentConfigs, which control ReplicationControllers
+	dc := &unstructured.Unstructured{}
+	dc.SetAPIVersion("apps.openshift.io/v1")
+	dc.SetKind("DeploymentConfig")
+	dc.SetName("app-duplik8ted")
+	dc.SetNamespace("default")
+	dc.SetUID("dc-uid")
+	client, dynamicClient := newOwnerClients(
+		&corev1.ReplicationController{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-duplik8ted-1",
+			Namespace:       "default",
+			UID:             "rc-uid",
+			OwnerReferences: newControllerRef(dc.GetAPIVersion(), dc.GetKind(), dc.GetName(), dc.GetUID()),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-duplik8ted-1-abc",
+			Namespace:       "default",
+			OwnerReferences: newControllerRef("v1", "ReplicationController", "app-duplik8ted-1", "rc-uid"),
+		}},
+	)
+
+	pod, err := WaitForOwnedPod(context.Background(), client, dynamicClient, dc, nil, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-duplik8ted-1-abc", pod.Name)
+}
+
+func Test_WaitForOwnedPod_Timeout(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-duplik8ted", Namespace: "default", UID: "sts-uid"},
+	}
+	client, dynamicClient := newOwnerClients(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "db-0",
+			Namespace:       "default",
+			OwnerReferences: newControllerRef("apps/v1", "StatefulSet", "db", "original-uid"),
+		}},
+	)
+
+	_, err := WaitForOwnedPod(context.Background(), client, dynamicClient, sts, nil, 500*time.Millisecond)
+	assert.EqualError(t, err, `no pod owned by "db-duplik8ted" created within 500ms`)
+}
+
+func Test_DuplicatedAnnotations(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", UID: "pod-uid", ResourceVersion: "42"}}
+	opts := core.DuplicateOpts{Command: []string{"sh"}, Creator: "alice", Note: "INC-1234"}
+	annotations := duplicatedAnnotations(
+		map[string]string{"team": "core"},
+		core.NewSourceObject(corev1.SchemeGroupVersion.WithKind("Pod"), pod),
+		opts,
+	)
+	assert.Equal(t, map[string]string{
+		"team":                                  "core",
+		core.ANNOTATION_SOURCE_API_VERSION:      "v1",
+		core.ANNOTATION_SOURCE_KIND:             "Pod",
+		core.ANNOTATION_SOURCE_NAME:             "nginx",
+		core.ANNOTATION_SOURCE_UID:              "pod-uid",
+		core.ANNOTATION_SOURCE_RESOURCE_VERSION: "42",
+		core.ANNOTATION_OPTIONS:                 `{"command":["sh"],"metadata":{}}`,
+		core.ANNOTATION_CREATOR:                 "alice",
+		core.ANNOTATION_NOTE:                    "INC-1234",
+	}, annotations)
+	assert.Equal(t, &core.SourceObject{
+		APIVersion:      "v1",
+		Kind:            "Pod",
+		Name:            "nginx",
+		UID:             "pod-uid",
+		ResourceVersion: "42",
+	}, core.ParseSourceObject(annotations))
+	// the creator and the note are recorded in their own annotations
+	opts.Creator = ""
+	opts.Note = ""
+	assert.Equal(t, &opts, core.ParseOptions(annotations))
+}
+
+// newSourceTemplate returns a Pod template whose labels are selected by newSourceSelector.
+func newSourceTemplate() corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api", "tier": "backend"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Image: "api"}}},
+	}
+}
+
+// newSourceSelector returns a selector using both match labels and match expressions.
+func newSourceSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app": "api"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend"}},
+		},
+	}
+}
+
+func Test_IsolatedLabels_Workloads(t *testing.T) {
+	ctx := context.Background()
+	workloads := []struct {
+		kind string
+		// source returns the workload to duplicate, named api, with the provided selector
+		source func(selector *metav1.LabelSelector) runtime.Object
+		// duplicate duplicates the workload and returns the selector and the template labels of its duplicate
+		duplicate func(client *fake.Clientset) (*metav1.LabelSelector, map[string]string, error)
+	}{
+		{
+			kind: "Deployment",
+			source: func(selector *metav1.LabelSelector) runtime.Object {
+				return &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+					Spec:       appsv1.DeploymentSpec{Selector: selector, Template: newSourceTemplate()},
+				}
+			},
+			duplicate: func(client *fake.Clientset) (*metav1.LabelSelector, map[string]string, error) {
+				deployClient := &DeploymentClient{clientset: client, ctx: ctx}
+				err := deployClient.Duplicate(core.DuplicableObject{Name: "api", Namespace: "default"}, core.DuplicateOpts{})
+				if err != nil {
+					return nil, nil, err
+				}
+				duplicated, err := client.AppsV1().Deployments("default").Get(ctx, "api-duplik8ted", metav1.GetOptions{})
+				if err != nil {
+					return nil, nil, err
+				}
+				return duplicated.Spec.Selector, duplicated.Spec.Template.Labels, nil
+			},
+		},
+		{
+			kind: "StatefulSet",
+			source: func(selector *metav1.LabelSelector) runtime.Object {
+				return &appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+					Spec:       appsv1.StatefulSetSpec{Selector: selector, Template: newSourceTemplate()},
+				}
+			},
+			duplicate: func(client *fake.Clientset) (*metav1.LabelSelector, map[string]string, error) {
+				statefulSetClient := &StatefulSetClient{clientset: client, ctx: ctx}
+				err := statefulSetClient.Duplicate(core.DuplicableObject{Name: "api", Namespace: "default"}, core.DuplicateOpts{})
+				if err != nil {
+					return nil, nil, err
+				}
+				duplicated, err := client.AppsV1().StatefulSets("default").Get(ctx, "api-duplik8ted", metav1.GetOptions{})
+				if err != nil {
+					return nil, nil, err
+				}
+				return duplicated.Spec.Selector, duplicated.Spec.Template.Labels, nil
+			},
+		},
+	}
+	selectors := []struct {
+		name     string
+		selector *metav1.LabelSelector
+	}{
+		{name: "match labels", selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+		// the match expressions would still select the Pods of the original workload
+		{name: "match expressions", selector: newSourceSelector()},
+	}
+	for _, w := range workloads {
+		for _, s := range selectors {
+			t.Run(w.kind+" "+s.name, func(t *testing.T) {
+				client := fake.NewClientset(w.source(s.selector))
+				selector, labels, err := w.duplicate(client)
+				assert.NoError(t, err)
+				assert.Equal(t, &metav1.LabelSelector{MatchLabels: core.NewInstanceLabels("api-duplik8ted")}, selector)
+				assert.Equal(t, core.NewInstanceLabels("api-duplik8ted"), labels)
+			})
+		}
+	}
+}
+
+func Test_LimitPodLifetime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ttl      time.Duration
+		deadline *int64
+		expected *int64
+	}{
+		{name: "no ttl", ttl: 0, deadline: nil, expected: nil},
+		{name: "no ttl keeps the deadline", ttl: 0, deadline: ptr.To[int64](30), expected: ptr.To[int64](30)},
+		{name: "ttl", ttl: 2 * time.Hour, deadline: nil, expected: ptr.To[int64](7200)},
+		{name: "shorter deadline", ttl: time.Hour, deadline: ptr.To[int64](30), expected: ptr.To[int64](30)},
+		{name: "longer deadline", ttl: time.Minute, deadline: ptr.To[int64](3600), expected: ptr.To[int64](60)},
+		{name: "sub-second ttl", ttl: time.Millisecond, deadline: nil, expected: ptr.To[int64](1)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			podSpec := corev1.PodSpec{ActiveDeadlineSeconds: tc.deadline}
+			limitPodLifetime(&podSpec, core.DuplicateOpts{TTL: tc.ttl})
+			assert.Equal(t, tc.expected, podSpec.ActiveDeadlineSeconds)
+		})
+	}
+}
+
+func Test_SessionShell(t *testing.T) {
+	pod := corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "idle", Env: []corev1.EnvVar{{Name: "PATH", Value: core.DebugToolsPath("")}}},
+		{Name: "app", Env: []corev1.EnvVar{{Name: "PATH", Value: "/opt/app/bin"}}},
+	}}}
+	shell := []string{"/duplik8s-tools/sh"}
+
+	testCases := []struct {
+		name      string
+		container string
+		opts      core.DuplicateOpts
+		expected  []string
+	}{
+		{name: "no debug tools", container: "app", opts: core.DuplicateOpts{}, expected: shell},
+		{name: "idle container", container: "idle", opts: core.DuplicateOpts{DebugTools: true}, expected: shell},
+		{
+			name:      "container keeping its PATH",
+			container: "app",
+			opts:      core.DuplicateOpts{DebugTools: true},
+			expected: []string{
+				"/duplik8s-tools/sh",
+				"-c",
+				`PATH="${PATH:+$PATH:}/duplik8s-tools"; export PATH; exec /duplik8s-tools/sh`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sessionShell(pod, tc.container, shell, tc.opts))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package janitor periodically deletes the duplicated resources that are expired or too old.
+package janitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"log/slog"
+	"time"
+)
+
+type Options struct {
+	// Namespace is the namespace of the collected resources. If empty, all namespaces are collected.
+	Namespace string
+	// Interval is the time between two collections.
+	Interval time.Duration
+	// MaxAge is the maximum age of the duplicated resources. If zero, only expired resources are deleted.
+	MaxAge time.Duration
+	// Abandoned indicates whether to delete the duplicated resources whose shell session has been abandoned.
+	Abandoned bool
+	// AbandonedGracePeriod is the time a shell session must have been abandoned for before its resources are deleted,
+	// so that the sessions whose lease briefly couldn't be renewed (e.g. because of a network blip) are not collected.
+	AbandonedGracePeriod time.Duration
+	// DryRun indicates whether to only log the resources that would be deleted.
+	DryRun bool
+}
+
+// Janitor deletes the duplicated resources whose TTL has expired or that are older than a maximum age,
+// and optionally the ones whose shell session has been abandoned.
+type Janitor struct {
+	client  core.Client
+	opts    Options
+	metrics *metrics
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+func New(client core.Client, opts Options, registerer prometheus.Registerer, logger *slog.Logger) (*Janitor, error) {
+	m, err := newMetrics(registerer)
+	if err != nil {
+		return nil, err
+	}
+	return &Janitor{
+		client:  client,
+		opts:    opts,
+		metrics: m,
+		logger:  logger,
+		now:     time.Now,
+	}, nil
+}
+
+// Run collects the duplicated resources every interval, until the context is done.
+func (j *Janitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := j.Collect(ctx); err != nil {
+			j.logger.Error("failed to collect duplicated resources", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect deletes the duplicated resources that are expired or older than the maximum age,
+// and updates the metrics.
+func (j *Janitor) Collect(ctx context.Context) error {
+	duplicated, err := j.client.ListDuplicated(ctx, j.opts.Namespace)
+	if err != nil {
+		j.metrics.errors.Inc()
+		return err
+	}
+
+	now := j.now()
+	j.metrics.duplicates.Reset()
+	var failed int
+	for _, obj := range duplicated {
+		kind := obj.ObjectKind.GroupVersionKind().Kind
+		reason := j.deletionReason(obj, now)
+		if reason == "" {
+			j.metrics.duplicates.WithLabelValues(obj.Namespace, kind).Inc()
+			continue
+		}
+
+		logger := j.logger.With("namespace", obj.Namespace, "kind", kind, "name", obj.Name, "reason", reason)
+		if j.opts.DryRun {
+			logger.Info("would delete duplicated resource")
+			j.metrics.duplicates.WithLabelValues(obj.Namespace, kind).Inc()
+			continue
+		}
+		err = j.client.Delete(ctx, obj, core.DeleteOpts{})
+		var finalizersErr *core.FinalizersError
+		if errors.As(err, &finalizersErr) {
+			logger.Warn("deletion of duplicated resource pending", "finalizers", finalizersErr.Finalizers)
+			j.metrics.deletions.WithLabelValues(obj.Namespace, kind, reason).Inc()
+			continue
+		}
+		if err != nil {
+			logger.Error("failed to delete duplicated resource", "error", err)
+			j.metrics.errors.Inc()
+			j.metrics.duplicates.WithLabelValues(obj.Namespace, kind).Inc()
+			failed++
+			continue
+		}
+		logger.Info("deleted duplicated resource")
+		j.metrics.deletions.WithLabelValues(obj.Namespace, kind, reason).Inc()
+	}
+	j.metrics.lastCollected.Set(float64(now.Unix()))
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d duplicated resources", failed)
+	}
+	return nil
+}
+
+// deletionReason returns why the provided object should be deleted, or an empty string if it should be kept.
+func (j *Janitor) deletionReason(obj core.DuplicatedObject, now time.Time) string {
+	if obj.Expired(now) {
+		return reasonExpired
+	}
+	if j.opts.Abandoned && obj.Abandoned(now.Add(-j.opts.AbandonedGracePeriod)) {
+		return reasonAbandoned
+	}
+	if j.opts.MaxAge > 0 && now.Sub(obj.CreationTimestamp.Time) > j.opts.MaxAge {
+		return reasonMaxAge
+	}
+	return ""
+}
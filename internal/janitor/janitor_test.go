@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package janitor
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/telemaco019/duplik8s/internal/core"
+	"github.com/telemaco019/duplik8s/internal/test/mocks"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newDuplicatedObject(name, kind string, age time.Duration, ttl time.Duration) core.DuplicatedObject {
+	obj := core.DuplicatedObject{
+		Name:              name,
+		Namespace:         "default",
+		ObjectKind:        &metav1.TypeMeta{},
+		CreationTimestamp: metav1.NewTime(now.Add(-age)),
+	}
+	obj.ObjectKind.SetGroupVersionKind(schema.GroupVersionKind{Kind: kind})
+	if ttl != 0 {
+		expiresAt := metav1.NewTime(obj.CreationTimestamp.Add(ttl))
+		obj.ExpiresAt = &expiresAt
+	}
+	return obj
+}
+
+func newJanitor(t *testing.T, client core.Client, opts Options) (*Janitor, *prometheus.Registry) {
+	registry := prometheus.NewRegistry()
+	j, err := New(client, opts, registry, slog.New(slog.DiscardHandler))
+	assert.NoError(t, err)
+	j.now = func() time.Time { return now }
+	return j, registry
+}
+
+func Test_Collect(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		newDuplicatedObject("expired", "Pod", 3*time.Hour, 2*time.Hour),
+		newDuplicatedObject("alive", "Pod", time.Hour, 2*time.Hour),
+		newDuplicatedObject("old", "Deployment", 48*time.Hour, 0),
+		newDuplicatedObject("recent", "Deployment", time.Hour, 0),
+	}
+	j, registry := newJanitor(t, client, Options{Interval: time.Minute, MaxAge: 24 * time.Hour})
+
+	err := j.Collect(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"expired", "old"}, deletedNames(client))
+
+	expected := `
+# HELP duplik8s_duplicates Number of duplicated resources, by namespace and kind.
+# TYPE duplik8s_duplicates gauge
+duplik8s_duplicates{kind="Deployment",namespace="default"} 1
+duplik8s_duplicates{kind="Pod",namespace="default"} 1
+# HELP duplik8s_janitor_deletions_total Number of duplicated resources deleted by the janitor, by namespace, kind and reason.
+# TYPE duplik8s_janitor_deletions_total counter
+duplik8s_janitor_deletions_total{kind="Deployment",namespace="default",reason="max_age"} 1
+duplik8s_janitor_deletions_total{kind="Pod",namespace="default",reason="expired"} 1
+`
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expected),
+		"duplik8s_duplicates",
+		"duplik8s_janitor_deletions_total",
+	)
+	assert.NoError(t, err)
+}
+
+func Test_Collect_DryRun(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	client.ListDuplicatedResult = []core.DuplicatedObject{
+		newDuplicatedObject("expired", "Pod", 3*time.Hour, 2*time.Hour),
+	}
+	j, _ := newJanitor(t, client, Options{Interval: time.Minute, DryRun: true})
+
+	err := j.Collect(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, client.Deleted)
+}
+
+func Test_Collect_Abandoned(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	abandoned := newDuplicatedObject("abandoned", "Pod", time.Hour, 0)
+	abandoned.SessionExpiresAt = &metav1.Time{Time: now.Add(-time.Minute)}
+	alive := newDuplicatedObject("alive", "Pod", time.Hour, 0)
+	alive.SessionExpiresAt = &metav1.Time{Time: now.Add(time.Minute)}
+	client.ListDuplicatedResult = []core.DuplicatedObject{abandoned, alive}
+
+	j, _ := newJanitor(t, client, Options{Interval: time.Minute})
+	assert.NoError(t, j.Collect(context.Background()))
+	assert.Empty(t, client.Deleted)
+
+	j, _ = newJanitor(t, client, Options{Interval: time.Minute, Abandoned: true})
+	assert.NoError(t, j.Collect(context.Background()))
+	assert.Equal(t, []string{"abandoned"}, deletedNames(client))
+}
+
+func Test_Collect_AbandonedGracePeriod(t *testing.T) {
+	client := mocks.NewPodClient(mocks.ListPodsResult{}, nil)
+	abandoned := newDuplicatedObject("abandoned", "Pod", time.Hour, 0)
+	abandoned.SessionExpiresAt = &metav1.Time{Time: now.Add(-20 * time.Minute)}
+	recent := newDuplicatedObject("recent", "Pod", time.Hour, 0)
+	recent.SessionExpiresAt = &metav1.Time{Time: now.Add(-time.Minute)}
+	client.ListDuplicatedResult = []core.DuplicatedObject{abandoned, recent}
+
+	j, _ := newJanitor(t, client, Options{Interval: time.Minute, Abandoned: true, AbandonedGracePeriod: 10 * time.Minute})
+	assert.NoError(t, j.Collect(context.Background()))
+	assert.Equal(t, []string{"abandoned"}, deletedNames(client))
+}
+
+func deletedNames(client *mocks.PodClient) []string {
+	names := make([]string, 0, len(client.Deleted))
+	for _, obj := range client.Deleted {
+		names = append(names, obj.Name)
+	}
+	return names
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package janitor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "duplik8s"
+
+// Deletion reasons
+const (
+	reasonExpired   = "expired"
+	reasonMaxAge    = "max_age"
+	reasonAbandoned = "abandoned"
+)
+
+type metrics struct {
+	duplicates    *prometheus.GaugeVec
+	deletions     *prometheus.CounterVec
+	errors        prometheus.Counter
+	lastCollected prometheus.Gauge
+}
+
+func newMetrics(registerer prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		duplicates: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "duplicates",
+			Help:      "Number of duplicated resources, by namespace and kind.",
+		}, []string{"namespace", "kind"}),
+		deletions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "janitor_deletions_total",
+			Help:      "Number of duplicated resources deleted by the janitor, by namespace, kind and reason.",
+		}, []string{"namespace", "kind", "reason"}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "janitor_errors_total",
+			Help:      "Number of errors while listing or deleting duplicated resources.",
+		}),
+		lastCollected: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "janitor_last_collection_timestamp_seconds",
+			Help:      "Time of the last collection of the duplicated resources.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.duplicates, m.deletions, m.errors, m.lastCollected} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
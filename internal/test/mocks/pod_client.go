@@ -18,10 +18,16 @@ package mocks
 
 import (
 	"context"
+	"errors"
 	"github.com/telemaco019/duplik8s/internal/core"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// CurrentUser is the user returned by default by PodClient.CurrentUser.
+const CurrentUser = "alice"
+
 type ListPodsResult struct {
 	Objs []core.DuplicableObject
 	Err  error
@@ -45,6 +51,20 @@ type PodClient struct {
 	ListPodsResult       ListPodsResult
 	DuplicatePodResult   error
 	ListDuplicatedResult []core.DuplicatedObject
+	// DuplicateOpts are the options of the last call to Duplicate
+	DuplicateOpts core.DuplicateOpts
+	// ListedNamespace is the namespace of the last call to ListDuplicated
+	ListedNamespace string
+	// Deleted are the objects deleted with Delete
+	Deleted []core.DuplicatedObject
+	// DeleteOpts are the options of the last call to Delete
+	DeleteOpts core.DeleteOpts
+	// DeleteErrors are the errors returned by Delete, by object name
+	DeleteErrors map[string]error
+	// Objects are the objects returned by Get
+	Objects []*unstructured.Unstructured
+	// User is the user returned by CurrentUser. If empty, CurrentUser returns an error.
+	User string
 }
 
 func NewPodClient(
@@ -55,6 +75,7 @@ func NewPodClient(
 		ListPodsResult:       ListPodsResult,
 		DuplicatePodResult:   DuplicatePodResult,
 		ListDuplicatedResult: make([]core.DuplicatedObject, 0),
+		User:                 CurrentUser,
 	}
 }
 
@@ -66,7 +87,8 @@ func (c *PodClient) ListDuplicable(
 	return c.ListPodsResult.Objs, c.ListPodsResult.Err
 }
 
-func (c *PodClient) Duplicate(_ core.DuplicableObject, __ core.DuplicateOpts) error {
+func (c *PodClient) Duplicate(_ core.DuplicableObject, opts core.DuplicateOpts) error {
+	c.DuplicateOpts = opts
 	return c.DuplicatePodResult
 }
 
@@ -74,9 +96,36 @@ func (c *PodClient) ListDuplicated(
 	ctx context.Context,
 	namespace string,
 ) ([]core.DuplicatedObject, error) {
+	c.ListedNamespace = namespace
 	return c.ListDuplicatedResult, nil
 }
 
-func (c *PodClient) Delete(ctx context.Context, obj core.DuplicatedObject) error {
+func (c *PodClient) Delete(ctx context.Context, obj core.DuplicatedObject, opts core.DeleteOpts) error {
+	c.DeleteOpts = opts
+	if err := c.DeleteErrors[obj.Name]; err != nil {
+		return err
+	}
+	c.Deleted = append(c.Deleted, obj)
 	return nil
 }
+
+func (c *PodClient) Get(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	namespace string,
+	name string,
+) (*unstructured.Unstructured, error) {
+	for _, obj := range c.Objects {
+		if obj.GroupVersionKind() == gvk && obj.GetNamespace() == namespace && obj.GetName() == name {
+			return obj, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: gvk.Kind}, name)
+}
+
+func (c *PodClient) CurrentUser(ctx context.Context) (string, error) {
+	if c.User == "" {
+		return "", errors.New("failed to determine the current user")
+	}
+	return c.User, nil
+}
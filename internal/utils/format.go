@@ -18,13 +18,36 @@ package utils
 
 import (
 	"fmt"
+	"github.com/charmbracelet/lipgloss"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
 	"time"
 )
 
+var (
+	diffHeaderStyle  = lipgloss.NewStyle().Bold(true)
+	diffHunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
 func FormatAge(t metav1.Time) string {
-	duration := time.Since(t.Time)
+	return formatDuration(time.Since(t.Time))
+}
+
+// FormatTTL returns the time left before the provided expiration time.
+func FormatTTL(expiresAt *metav1.Time) string {
+	if expiresAt == nil {
+		return "-"
+	}
+	ttl := time.Until(expiresAt.Time)
+	if ttl <= 0 {
+		return "expired"
+	}
+	return formatDuration(ttl)
+}
 
+func formatDuration(duration time.Duration) string {
 	if duration.Hours() >= 24 {
 		return fmt.Sprintf("%dd", int(duration.Hours()/24))
 	} else if duration.Hours() >= 1 {
@@ -34,3 +57,21 @@ func FormatAge(t metav1.Time) string {
 	}
 	return fmt.Sprintf("%ds", int(duration.Seconds()))
 }
+
+// ColorizeDiff colors the lines of the provided unified diff. Colors are omitted if the terminal doesn't support them.
+func ColorizeDiff(diff string) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			lines[i] = diffHeaderStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = diffHunkStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = diffAddedStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = diffRemovedStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
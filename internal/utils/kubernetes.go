@@ -17,13 +17,9 @@
 package utils
 
 import (
-	"context"
-	"fmt"
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"os"
 	"path/filepath"
-	"time"
 
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -36,13 +32,7 @@ import (
 
 // NewClientset creates a new kubernetes clientset
 func NewClientset(kubeconfig, context string) (*kubernetes.Clientset, error) {
-	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
-		&clientcmd.ConfigOverrides{
-			ClusterInfo:    clientcmdapi.Cluster{Server: ""},
-			CurrentContext: context,
-		},
-	).ClientConfig()
+	config, err := NewRestConfig(kubeconfig, context)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +45,14 @@ func NewClientset(kubeconfig, context string) (*kubernetes.Clientset, error) {
 	return clientSet, nil
 }
 
-func getKubeClientConfig(kubeconfig, context string) (*rest.Config, error) {
+// NewRestConfig creates the REST config used by all the kubernetes clients
+func NewRestConfig(kubeconfig, context string) (*rest.Config, error) {
+	// when running in a Pod without kubeconfig, use the service account of the Pod
+	if _, err := os.Stat(kubeconfig); os.IsNotExist(err) && context == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			return config, nil
+		}
+	}
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
 		&clientcmd.ConfigOverrides{
@@ -65,8 +62,27 @@ func getKubeClientConfig(kubeconfig, context string) (*rest.Config, error) {
 	).ClientConfig()
 }
 
+// KubeconfigUser returns the name of the user of the provided kubeconfig context,
+// or of the current context if empty. An empty string is returned if the user is unknown.
+func KubeconfigUser(kubeconfig, context string) string {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	).RawConfig()
+	if err != nil {
+		return ""
+	}
+	if context == "" {
+		context = config.CurrentContext
+	}
+	if kubeContext, ok := config.Contexts[context]; ok {
+		return kubeContext.AuthInfo
+	}
+	return ""
+}
+
 func NewDynamicClient(kubeconfig, context string) (*dynamic.DynamicClient, error) {
-	config, err := getKubeClientConfig(kubeconfig, context)
+	config, err := NewRestConfig(kubeconfig, context)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +105,7 @@ func GetKubeconfigPath() string {
 }
 
 func NewDiscoveryClient(kubeconfig, context string) (*discovery.DiscoveryClient, error) {
-	config, err := getKubeClientConfig(kubeconfig, context)
+	config, err := NewRestConfig(kubeconfig, context)
 	if err != nil {
 		return nil, err
 	}
@@ -111,26 +127,6 @@ func IsPodReady(pod *v1.Pod) bool {
 	return false
 }
 
-func WaitUntilPodReady(ctx context.Context, client kubernetes.Interface, pod v1.Pod, timeout time.Duration) error {
-	watchCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	watch, err := client.CoreV1().Pods(pod.Namespace).Watch(watchCtx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", pod.Name),
-	})
-	if err != nil {
-		return err
-	}
-	defer watch.Stop()
-
-	for event := range watch.ResultChan() {
-		if pod, ok := event.Object.(*v1.Pod); ok && IsPodReady(pod) {
-			return nil
-		}
-	}
-	return fmt.Errorf("pod %s not ready within timeout", pod.Name)
-}
-
 type KubeOptions struct {
 	Kubeconfig  string
 	Kubecontext string
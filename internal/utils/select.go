@@ -17,6 +17,7 @@
 package utils
 
 import (
+	"fmt"
 	"github.com/charmbracelet/huh"
 	"github.com/telemaco019/duplik8s/internal/core"
 )
@@ -34,3 +35,36 @@ func SelectItem(items []core.DuplicableObject, selectMessage string) (core.Dupli
 		Run()
 	return selected, err
 }
+
+func SelectString(items []string, selectMessage string) (string, error) {
+	var selected string
+	err := huh.NewSelect[string]().
+		Title(selectMessage).
+		Options(huh.NewOptions(items...)...).
+		Value(&selected).
+		Run()
+	return selected, err
+}
+
+// SelectDuplicated prompts the user to select some of the provided duplicated objects, all selected by default.
+func SelectDuplicated(items []core.DuplicatedObject, selectMessage string) ([]core.DuplicatedObject, error) {
+	var selected []int
+	options := make([]huh.Option[int], len(items))
+	for i, o := range items {
+		label := fmt.Sprintf("%s %s/%s", o.ObjectKind.GroupVersionKind().Kind, o.Namespace, o.Name)
+		options[i] = huh.NewOption(label, i).Selected(true)
+	}
+	err := huh.NewMultiSelect[int]().
+		Title(selectMessage).
+		Options(options...).
+		Value(&selected).
+		Run()
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]core.DuplicatedObject, 0, len(selected))
+	for _, i := range selected {
+		objs = append(objs, items[i])
+	}
+	return objs, nil
+}
@@ -17,14 +17,242 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/term"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
 	"os"
-	"os/exec"
+	"path"
+	"strings"
 )
 
-func RunInteractive(cmd []string) error {
-	c := exec.Command(cmd[0], cmd[1:]...)
-	c.Stdin = os.Stdin
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-	return c.Run()
+// ExecInteractive runs the provided command in a container of the Pod, attaching the standard
+// streams of the current process. If the standard input is a terminal, a TTY is allocated and
+// kept in sync with the size of the local terminal.
+func ExecInteractive(
+	ctx context.Context,
+	config *rest.Config,
+	clientset kubernetes.Interface,
+	pod v1.Pod,
+	container string,
+	command []string,
+) error {
+	fd := int(os.Stdin.Fd())
+	tty := term.IsTerminal(fd)
+
+	executor, err := newExecutor(config, clientset, pod, &v1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     true,
+		Stdout:    true,
+		Stderr:    !tty,
+		TTY:       tty,
+	})
+	if err != nil {
+		return err
+	}
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Tty:    tty,
+	}
+	if tty {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+
+		sizeQueue := newTerminalSizeQueue(ctx, int(os.Stdout.Fd()))
+		defer sizeQueue.stop()
+		streamOpts.TerminalSizeQueue = sizeQueue
+	} else {
+		streamOpts.Stderr = os.Stderr
+	}
+
+	err = executor.StreamWithContext(ctx, streamOpts)
+
+	// the exit code of the last command run in the shell is not an error of the session
+	var exitErr exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		return nil
+	}
+	return err
+}
+
+// ExecCommand runs the provided command in a container of the Pod, discarding its output.
+func ExecCommand(
+	ctx context.Context,
+	config *rest.Config,
+	clientset kubernetes.Interface,
+	pod v1.Pod,
+	container string,
+	command []string,
+) error {
+	executor, err := newExecutor(config, clientset, pod, &v1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdout:    true,
+		Stderr:    true,
+	})
+	if err != nil {
+		return err
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	})
+}
+
+// DetectShell returns the first shell available in a container of the Pod, according to the provided
+// preference order. Shells can be provided either as names (e.g. "bash"), looked up in the
+// standard binary directories and in busybox, or as absolute paths.
+func DetectShell(
+	ctx context.Context,
+	config *rest.Config,
+	clientset kubernetes.Interface,
+	pod v1.Pod,
+	container string,
+	preference []string,
+) ([]string, error) {
+	shell, err := detectShell(ctx, preference, func(command []string) error {
+		return ExecCommand(ctx, config, clientset, pod, container, command)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no shell found in container %q of pod %q (%w)", container, pod.Name, err)
+	}
+	return shell, nil
+}
+
+// detectShell returns the first shell candidate that exits successfully when run with the provided function.
+func detectShell(ctx context.Context, preference []string, run func(command []string) error) ([]string, error) {
+	tried := make([]string, 0)
+	var lastErr error
+	for _, shell := range preference {
+		for _, candidate := range shellCandidates(shell) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			tried = append(tried, strings.Join(candidate, " "))
+			probe := append(append([]string{}, candidate...), "-c", "exit 0")
+			err := run(probe)
+			if err == nil {
+				return candidate, nil
+			}
+			// the shell doesn't exist or can't be executed: depending on the container runtime, this is
+			// either a non-zero exit code or a failure to start the command, so try the next one
+			lastErr = err
+		}
+	}
+	if lastErr == nil {
+		return nil, errors.New("no shell provided")
+	}
+	return nil, fmt.Errorf("tried %s, last error: %w", strings.Join(tried, ", "), lastErr)
+}
+
+// shellBinDirs are the directories where shells are looked up when provided by name
+var shellBinDirs = []string{"/bin", "/usr/bin", "/usr/local/bin"}
+
+func shellCandidates(shell string) [][]string {
+	if strings.Contains(shell, "/") {
+		return [][]string{{shell}}
+	}
+	candidates := make([][]string, 0, len(shellBinDirs)+1)
+	for _, dir := range shellBinDirs {
+		candidates = append(candidates, []string{path.Join(dir, shell)})
+	}
+	// busybox images may not have the links to the applets
+	return append(candidates, []string{"/bin/busybox", shell})
+}
+
+// newExecutor returns an executor running a command in the Pod, using WebSockets
+// and falling back to SPDY if the API server does not support them.
+func newExecutor(
+	config *rest.Config,
+	clientset kubernetes.Interface,
+	pod v1.Pod,
+	opts *v1.PodExecOptions,
+) (remotecommand.Executor, error) {
+	req := clientset.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(opts, scheme.ParameterCodec)
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return nil, err
+	}
+	websocketExecutor, err := remotecommand.NewWebSocketExecutor(config, "GET", req.URL().String())
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(websocketExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
+
+// terminalSizeQueue notifies the remote TTY about the size changes of the local terminal.
+type terminalSizeQueue struct {
+	fd      int
+	last    remotecommand.TerminalSize
+	resizes chan remotecommand.TerminalSize
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func newTerminalSizeQueue(ctx context.Context, fd int) *terminalSizeQueue {
+	ctx, cancel := context.WithCancel(ctx)
+	q := &terminalSizeQueue{
+		fd:      fd,
+		resizes: make(chan remotecommand.TerminalSize, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	// send the initial size, then watch for changes
+	q.notify()
+	go watchTerminalResize(ctx, q.notify)
+	return q
+}
+
+func (q *terminalSizeQueue) notify() {
+	width, height, err := term.GetSize(q.fd)
+	if err != nil {
+		return
+	}
+	size := remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+	if size == q.last {
+		return
+	}
+	q.last = size
+	// drop the pending size, if any, as it is outdated
+	select {
+	case <-q.resizes:
+	default:
+	}
+	q.resizes <- size
+}
+
+// Next returns the next terminal size, or nil once the queue is stopped.
+func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-q.resizes:
+		return &size
+	case <-q.ctx.Done():
+		return nil
+	}
+}
+
+func (q *terminalSizeQueue) stop() {
+	q.cancel()
 }
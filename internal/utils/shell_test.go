@@ -0,0 +1,106 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/exec"
+	"strings"
+	"testing"
+)
+
+func Test_DetectShell(t *testing.T) {
+	testCases := []struct {
+		name        string
+		preference  []string
+		available   string
+		failure     error
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:       "first preference",
+			preference: []string{"bash", "sh"},
+			available:  "/bin/bash",
+			expected:   []string{"/bin/bash"},
+		},
+		{
+			name:       "non-zero exit code",
+			preference: []string{"bash", "sh"},
+			available:  "/usr/bin/sh",
+			failure:    exec.CodeExitError{Err: errors.New("command terminated"), Code: 127},
+			expected:   []string{"/usr/bin/sh"},
+		},
+		{
+			name:       "command not started",
+			preference: []string{"bash", "sh"},
+			available:  "/usr/bin/sh",
+			failure:    errors.New(`OCI runtime exec failed: exec: "/bin/bash": stat /bin/bash: no such file or directory`),
+			expected:   []string{"/usr/bin/sh"},
+		},
+		{
+			name:       "busybox",
+			preference: []string{"ash"},
+			available:  "/bin/busybox ash",
+			failure:    errors.New("not found"),
+			expected:   []string{"/bin/busybox", "ash"},
+		},
+		{
+			name:       "absolute path",
+			preference: []string{"/opt/bin/zsh"},
+			available:  "/opt/bin/zsh",
+			expected:   []string{"/opt/bin/zsh"},
+		},
+		{
+			name:        "no shell",
+			preference:  []string{"bash", "/opt/bin/zsh"},
+			failure:     errors.New("not found"),
+			expectedErr: "tried /bin/bash, /usr/bin/bash, /usr/local/bin/bash, /bin/busybox bash, /opt/bin/zsh, last error: not found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shell, err := detectShell(context.Background(), tc.preference, func(command []string) error {
+				assert.Equal(t, []string{"-c", "exit 0"}, command[len(command)-2:])
+				if strings.Join(command[:len(command)-2], " ") == tc.available {
+					return nil
+				}
+				return tc.failure
+			})
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, shell)
+		})
+	}
+}
+
+func Test_DetectShell_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs int
+	_, err := detectShell(ctx, []string{"bash", "sh"}, func(command []string) error {
+		runs++
+		cancel()
+		return errors.New("connection reset")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, runs)
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"time"
+)
+
+// fatalWaitingReasons are the reasons of waiting containers that won't start without a change to the Pod.
+var fatalWaitingReasons = map[string]bool{
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"ErrImageNeverPull":          true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+}
+
+// fatalEventReasons are the reasons of the Events reporting that the Pod can't be scheduled.
+var fatalEventReasons = map[string]bool{
+	"FailedScheduling": true,
+	"Unschedulable":    true,
+}
+
+// WaitUntilPodRunning waits until the provided container of the Pod is running, printing the Events of the Pod
+// and the state changes of its containers. Probes are not taken into account, since they are usually removed
+// from duplicated Pods.
+//
+// It fails as soon as the Pod can't be scheduled or one of its containers can't start
+// (e.g. ImagePullBackOff, CrashLoopBackOff), instead of waiting for the timeout.
+func WaitUntilPodRunning(
+	ctx context.Context,
+	client kubernetes.Interface,
+	pod v1.Pod,
+	container string,
+	timeout time.Duration,
+) error {
+	timeoutCtx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	defer cancelTimeout()
+	watchCtx, cancel := context.WithCancelCause(timeoutCtx)
+	defer cancel(nil)
+
+	go func() {
+		err := watchPodEvents(watchCtx, client, pod)
+		if err != nil {
+			cancel(err)
+		}
+	}()
+
+	var lastStatus string
+	states := make(map[string]string)
+	lw := newFieldSelectorListWatch(
+		fields.OneTermEqualSelector("metadata.name", pod.Name),
+		func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Pods(pod.Namespace).List(ctx, options)
+		},
+		func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Pods(pod.Namespace).Watch(ctx, options)
+		},
+	)
+	_, err := watchtools.UntilWithSync(watchCtx, lw, &v1.Pod{}, nil, func(event watch.Event) (bool, error) {
+		p, ok := event.Object.(*v1.Pod)
+		if !ok || p.UID != pod.UID {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			return false, fmt.Errorf("pod %q has been deleted", pod.Name)
+		}
+		running, status, err := checkPodStatus(p, container, states)
+		lastStatus = status
+		return running, err
+	})
+	if err == nil {
+		return nil
+	}
+	if cause := context.Cause(watchCtx); cause != nil && !errors.Is(cause, context.Canceled) &&
+		!errors.Is(cause, context.DeadlineExceeded) {
+		return cause
+	}
+	if timeoutCtx.Err() != nil {
+		if lastStatus != "" {
+			return fmt.Errorf("pod %q not running within %s, last status: %s", pod.Name, timeout, lastStatus)
+		}
+		return fmt.Errorf("pod %q not running within %s", pod.Name, timeout)
+	}
+	return err
+}
+
+// checkPodStatus prints the state changes of the containers of the Pod, and returns whether the provided
+// container is running along with a short description of the Pod status. An error is returned if the
+// Pod can't start.
+func checkPodStatus(pod *v1.Pod, container string, states map[string]string) (bool, string, error) {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodScheduled && cond.Status == v1.ConditionFalse && cond.Reason == v1.PodReasonUnschedulable {
+			return false, "", fmt.Errorf("pod %q can't be scheduled: %s", pod.Name, cond.Message)
+		}
+	}
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return false, "", fmt.Errorf("pod %q terminated with phase %s", pod.Name, pod.Status.Phase)
+	}
+
+	status := string(pod.Status.Phase)
+	running := false
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, s := range statuses {
+		state := describeContainerState(s.State)
+		if states[s.Name] != state {
+			fmt.Printf("  container %q: %s\n", s.Name, state)
+			states[s.Name] = state
+		}
+		if s.State.Waiting != nil && fatalWaitingReasons[s.State.Waiting.Reason] {
+			return false, "", fmt.Errorf(
+				"container %q of pod %q can't start: %s: %s",
+				s.Name,
+				pod.Name,
+				s.State.Waiting.Reason,
+				s.State.Waiting.Message,
+			)
+		}
+		if s.State.Waiting != nil && s.State.Waiting.Reason != "" {
+			status = fmt.Sprintf("container %q %s", s.Name, state)
+		}
+		if s.Name == container && s.State.Running != nil {
+			running = true
+		}
+	}
+	return running && pod.Status.Phase == v1.PodRunning, status, nil
+}
+
+func describeContainerState(state v1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "running"
+	case state.Terminated != nil:
+		return fmt.Sprintf("terminated (%s, exit code %d)", state.Terminated.Reason, state.Terminated.ExitCode)
+	case state.Waiting != nil && state.Waiting.Reason != "":
+		return fmt.Sprintf("waiting (%s)", state.Waiting.Reason)
+	default:
+		return "waiting"
+	}
+}
+
+// watchPodEvents prints the Events of the provided Pod until the context is done.
+// An error is returned as soon as an Event reports that the Pod can't be scheduled.
+func watchPodEvents(ctx context.Context, client kubernetes.Interface, pod v1.Pod) error {
+	lw := newFieldSelectorListWatch(
+		fields.Set{"involvedObject.kind": "Pod", "involvedObject.name": pod.Name}.AsSelector(),
+		func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Events(pod.Namespace).List(ctx, options)
+		},
+		func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Events(pod.Namespace).Watch(ctx, options)
+		},
+	)
+	seen := make(map[string]int32)
+	_, err := watchtools.UntilWithSync(ctx, lw, &v1.Event{}, nil, func(e watch.Event) (bool, error) {
+		event, ok := e.Object.(*v1.Event)
+		if !ok || e.Type == watch.Deleted || event.InvolvedObject.UID != pod.UID {
+			return false, nil
+		}
+		// events are aggregated, only print the new occurrences
+		if count, ok := seen[event.Name]; ok && count == event.Count {
+			return false, nil
+		}
+		seen[event.Name] = event.Count
+		fmt.Printf("  event %s: %s\n", event.Reason, event.Message)
+		if fatalEventReasons[event.Reason] {
+			return false, fmt.Errorf("pod %q can't be scheduled: %s", pod.Name, event.Message)
+		}
+		return false, nil
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func newFieldSelectorListWatch(
+	selector fields.Selector,
+	list func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error),
+	watchFunc func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error),
+) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector.String()
+			return list(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector.String()
+			return watchFunc(ctx, options)
+		},
+	}
+}
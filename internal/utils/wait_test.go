@@ -0,0 +1,90 @@
+/*
+ * Copyright 2025 Michele Zanotti <m.zanotti019@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func newPod(phase v1.PodPhase, state v1.ContainerState) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-duplik8ted", Namespace: "default", UID: "pod-uid"},
+		Status: v1.PodStatus{
+			Phase:             phase,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "app", State: state}},
+		},
+	}
+}
+
+func Test_WaitUntilPodRunning_Running(t *testing.T) {
+	pod := newPod(v1.PodRunning, v1.ContainerState{Running: &v1.ContainerStateRunning{}})
+	client := fake.NewClientset(pod)
+
+	err := WaitUntilPodRunning(context.Background(), client, *pod, "app", 5*time.Second)
+	assert.NoError(t, err)
+}
+
+func Test_WaitUntilPodRunning_ImagePullBackOff(t *testing.T) {
+	pod := newPod(v1.PodPending, v1.ContainerState{Waiting: &v1.ContainerStateWaiting{
+		Reason:  "ImagePullBackOff",
+		Message: `Back-off pulling image "app:missing"`,
+	}})
+	client := fake.NewClientset(pod)
+
+	err := WaitUntilPodRunning(context.Background(), client, *pod, "app", 5*time.Second)
+	assert.EqualError(
+		t,
+		err,
+		`container "app" of pod "app-duplik8ted" can't start: ImagePullBackOff: Back-off pulling image "app:missing"`,
+	)
+}
+
+func Test_WaitUntilPodRunning_FailedScheduling(t *testing.T) {
+	pod := newPod(v1.PodPending, v1.ContainerState{})
+	client := fake.NewClientset(pod, &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-duplik8ted.1", Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{
+			Kind: "Pod",
+			Name: pod.Name,
+			UID:  pod.UID,
+		},
+		Reason:  "FailedScheduling",
+		Message: "0/3 nodes are available: 3 Insufficient cpu.",
+		Count:   1,
+	})
+
+	err := WaitUntilPodRunning(context.Background(), client, *pod, "app", 5*time.Second)
+	assert.EqualError(t, err, `pod "app-duplik8ted" can't be scheduled: 0/3 nodes are available: 3 Insufficient cpu.`)
+}
+
+func Test_WaitUntilPodRunning_Timeout(t *testing.T) {
+	pod := newPod(v1.PodPending, v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}})
+	client := fake.NewClientset(pod)
+
+	err := WaitUntilPodRunning(context.Background(), client, *pod, "app", 500*time.Millisecond)
+	assert.EqualError(
+		t,
+		err,
+		`pod "app-duplik8ted" not running within 500ms, last status: container "app" waiting (ContainerCreating)`,
+	)
+}